@@ -308,9 +308,11 @@ func getSystemPrompt() string {
 
 6. 事件触发：
    - triggerCondition应该设置在正常值的边界附近
+   - triggerCondition是引用属性标识符的表达式，支持比较(>、>=、<、<=、==、!=)、&&、||、!、括号、四则运算和abs/min/max等函数，字符串用单引号括起，如 temperature >= 85 && speed > 2000
    - cooldown通常设置在300-600秒之间，避免事件触发过于频繁
    - 对于严重告警，可以设置较短的cooldown（如60秒）
    - 对于提示性事件，可以设置较长的cooldown（如600秒）
+   - 事件的输出参数(outputData)默认取同名属性的当前值，与属性不同名的参数在params中配置取值方法(property、constant、expression)，如 "params": {"level": {"method": "constant", "value": 1}}
 
 注意：
 1. 必须生成合法的JSON格式
@@ -36,8 +36,19 @@ func main() {
 	templatePath := flag.String("template-path", "configs/device_templates", "设备模板路径")
 	devicePath := flag.String("device-path", "configs", "设备配置目录路径（简化模式）")
 	webEnabled := flag.Bool("web", true, "是否启用Web管理界面")
+	seed := flag.Int64("seed", 0, "随机种子（TSL模拟器模式可选，用于复现模拟数据）")
+	stateDir := flag.String("state-dir", "", "模拟状态保存目录（TSL模拟器和简化模式，默认不保存）")
+	fresh := flag.Bool("fresh", false, "全新启动，忽略并覆盖已保存的模拟状态")
 	flag.Parse()
 
+	// 仅在显式指定时使用-seed参数
+	var seedOverride *int64
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seedOverride = seed
+		}
+	})
+
 	// 加载应用配置
 	appCfg, err := appConfig.LoadConfigFromFile(*configFile)
 	if err != nil {
@@ -137,7 +148,7 @@ func main() {
 			}
 		}
 		
-		if err := runSimulatorMode(framework, appCfg, *productType, *tslFile, *ruleFile); err != nil {
+		if err := runSimulatorMode(framework, appCfg, *productType, *tslFile, *ruleFile, seedOverride, *stateDir, *fresh); err != nil {
 			log.Fatal("Failed to run simulator mode:", err)
 		}
 
@@ -153,19 +164,19 @@ func main() {
 
 	case "multi":
 		// 多设备管理器模式
-		if err := runMultiDeviceMode(*multiConfigFile, *templatePath, *webEnabled); err != nil {
+		if err := runMultiDeviceMode(*multiConfigFile, *templatePath, *webEnabled, *fresh); err != nil {
 			log.Fatal("Failed to run multi-device mode:", err)
 		}
 
 	case "process":
 		// 多进程管理器模式
-		if err := runProcessMode(*multiConfigFile, *templatePath, *webEnabled); err != nil {
+		if err := runProcessMode(*multiConfigFile, *templatePath, *webEnabled, *fresh); err != nil {
 			log.Fatal("Failed to run process mode:", err)
 		}
 
 	case "simple":
 		// 简化多设备模式
-		if err := runSimpleMode(*devicePath, *webEnabled); err != nil {
+		if err := runSimpleMode(*devicePath, *webEnabled, *stateDir, *fresh); err != nil {
 			log.Fatal("Failed to run simple mode:", err)
 		}
 
@@ -196,7 +207,7 @@ func runSensorMode(framework core.Framework, appCfg core.Config) error {
 }
 
 // runSimulatorMode 运行TSL模拟器模式
-func runSimulatorMode(framework core.Framework, appCfg core.Config, productType, tslFile, ruleFile string) error {
+func runSimulatorMode(framework core.Framework, appCfg core.Config, productType, tslFile, ruleFile string, seed *int64, stateDir string, fresh bool) error {
 	// 获取当前工作目录
 	workDir, err := os.Getwd()
 	if err != nil {
@@ -256,6 +267,20 @@ func runSimulatorMode(framework core.Framework, appCfg core.Config, productType,
 	// 设置上报间隔（可以从配置中读取）
 	simulatedDevice.SetUploadInterval(30 * time.Second)
 
+	// 指定了随机种子时覆盖规则中的种子
+	if seed != nil {
+		simulatedDevice.SetSeed(*seed)
+	}
+	log.Printf("Simulated device random seed: %d", simulatedDevice.GetSeed())
+
+	// 恢复并持久化模拟状态
+	if stateDir != "" {
+		stateFile := simulator.DeviceStateFile(stateDir, appCfg.Device.ProductKey, appCfg.Device.DeviceName)
+		if err := simulatedDevice.EnableStatePersistence(stateFile, fresh); err != nil {
+			log.Printf("恢复模拟状态失败: %v", err)
+		}
+	}
+
 	// 注册设备
 	if err := framework.RegisterDevice(simulatedDevice); err != nil {
 		return err
@@ -266,11 +291,12 @@ func runSimulatorMode(framework core.Framework, appCfg core.Config, productType,
 }
 
 // runMultiDeviceMode 运行多设备管理器模式
-func runMultiDeviceMode(configFile, templatePath string, webEnabled bool) error {
+func runMultiDeviceMode(configFile, templatePath string, webEnabled, fresh bool) error {
 	log.Println("启动多设备管理器模式...")
 
 	// 创建设备管理器
 	deviceManager := manager.NewDeviceManager(configFile, templatePath)
+	deviceManager.SetFreshStart(fresh)
 
 	// 启动设备管理器
 	if err := deviceManager.Start(); err != nil {
@@ -314,7 +340,7 @@ func runMultiDeviceMode(configFile, templatePath string, webEnabled bool) error
 }
 
 // runProcessMode 运行多进程管理器模式
-func runProcessMode(configFile, templatePath string, webEnabled bool) error {
+func runProcessMode(configFile, templatePath string, webEnabled, fresh bool) error {
 	log.Println("启动多进程管理器模式...")
 
 	// 获取当前可执行文件路径
@@ -331,6 +357,7 @@ func runProcessMode(configFile, templatePath string, webEnabled bool) error {
 
 	// 创建进程管理器
 	processManager := process.NewProcessManager(executablePath, workDir)
+	processManager.SetFreshStart(fresh)
 
 	// 加载配置
 	if err := processManager.LoadConfig(configFile, templatePath); err != nil {
@@ -394,7 +421,7 @@ func startWebServerForProcess(_ *process.ProcessManager) error {
 }
 
 // runSimpleMode 运行简化多设备模式
-func runSimpleMode(devicePath string, webEnabled bool) error {
+func runSimpleMode(devicePath string, webEnabled bool, stateDir string, fresh bool) error {
 	log.Println("启动简化多设备模式...")
 
 	// 获取当前可执行文件路径
@@ -444,14 +471,19 @@ func runSimpleMode(devicePath string, webEnabled bool) error {
 		log.Printf("设备[%s] - TSL路径: %s", deviceDir, tslFile)
 		log.Printf("设备[%s] - 规则路径: %s", deviceDir, ruleFile)
 		
-		// 创建进程
-		cmd := exec.Command(executablePath,
+		// 创建进程，指定了状态目录时各设备的状态文件按三元组保存在该目录下
+		args := []string{
 			"-mode", "simulator",
 			"-product", "auto-detect",
 			"-config", configFile,
 			"-tsl", tslFile,
 			"-rule", ruleFile,
-		)
+			"-state-dir", stateDir,
+		}
+		if fresh {
+			args = append(args, "-fresh")
+		}
+		cmd := exec.Command(executablePath, args...)
 		
 		// 设置工作目录
 		cmd.Dir = workDir
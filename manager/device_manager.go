@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"znb/iot-uplink-gen/simulator"
 )
 
 // DeviceManager 设备管理器
@@ -26,6 +28,7 @@ type DeviceManager struct {
 	cancel        context.CancelFunc
 	mutex         sync.RWMutex
 	running       bool
+	freshStart    bool // 启动时清除已保存的模拟状态
 	
 	// 监控和日志
 	logBuffer     []LogEntry
@@ -82,6 +85,11 @@ func NewDeviceManager(configPath, templatePath string) *DeviceManager {
 	}
 }
 
+// SetFreshStart 设置是否全新启动，需在Start前调用
+func (dm *DeviceManager) SetFreshStart(fresh bool) {
+	dm.freshStart = fresh
+}
+
 // LoadConfig 加载配置
 func (dm *DeviceManager) LoadConfig() error {
 	dm.mutex.Lock()
@@ -159,6 +167,13 @@ func (dm *DeviceManager) Start() error {
 	}
 	dm.log("info", "manager", "配置加载完成")
 
+	// 全新启动时清除已保存的模拟状态
+	if dm.freshStart {
+		if err := simulator.ClearStateDir(dm.config.GlobalConfig.GetStateDir()); err != nil {
+			dm.log("warn", "manager", fmt.Sprintf("清除模拟状态失败: %v", err))
+		}
+	}
+
 	// 启动日志处理器
 	go dm.logProcessor()
 
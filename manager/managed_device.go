@@ -294,6 +294,18 @@ func (md *ManagedDevice) runDeviceInternal() error {
 	md.simulatedDevice.SetFramework(md.framework)
 	interval := time.Duration(md.deviceInfo.GetUploadInterval(md.globalConfig.DefaultInterval)) * time.Second
 	md.simulatedDevice.SetUploadInterval(interval)
+	if md.deviceInfo.Seed != nil {
+		md.simulatedDevice.SetSeed(*md.deviceInfo.Seed)
+	}
+	md.log("info", fmt.Sprintf("随机种子: %d", md.simulatedDevice.GetSeed()))
+
+	// 配置了状态目录时恢复上次保存的模拟状态
+	if stateDir := md.globalConfig.GetStateDir(); stateDir != "" {
+		stateFile := simulator.DeviceStateFile(stateDir, md.deviceInfo.ProductKey, md.deviceInfo.DeviceName)
+		if err := md.simulatedDevice.EnableStatePersistence(stateFile, false); err != nil {
+			md.log("warn", fmt.Sprintf("恢复模拟状态失败: %v", err))
+		}
+	}
 
 	// 设置日志回调
 	md.simulatedDevice.SetLogCallback(func(msg string) {
@@ -27,14 +27,15 @@ type DeviceGroup struct {
 
 // DeviceInfo 设备信息
 type DeviceInfo struct {
-	DeviceID     string                 `json:"device_id"`     // 设备唯一标识
-	DeviceName   string                 `json:"device_name"`   // 设备名称
-	ProductKey   string                 `json:"product_key"`   // 产品密钥
-	DeviceSecret string                 `json:"device_secret"` // 设备密钥
-	Enabled      bool                   `json:"enabled"`       // 是否启用
-	CustomConfig map[string]interface{} `json:"custom_config"` // 自定义配置
-	Interval     int                    `json:"interval"`      // 上报间隔(秒)
-	Tags         []string               `json:"tags"`          // 设备标签
+	DeviceID     string                 `json:"device_id"`      // 设备唯一标识
+	DeviceName   string                 `json:"device_name"`    // 设备名称
+	ProductKey   string                 `json:"product_key"`    // 产品密钥
+	DeviceSecret string                 `json:"device_secret"`  // 设备密钥
+	Enabled      bool                   `json:"enabled"`        // 是否启用
+	CustomConfig map[string]interface{} `json:"custom_config"`  // 自定义配置
+	Interval     int                    `json:"interval"`       // 上报间隔(秒)
+	Tags         []string               `json:"tags"`           // 设备标签
+	Seed         *int64                 `json:"seed,omitempty"` // 随机种子(可选)，用于复现模拟数据
 }
 
 // GlobalConfig 全局配置
@@ -43,6 +44,7 @@ type GlobalConfig struct {
 	Web         WebConfig        `json:"web"`
 	Logging     LoggingConfig    `json:"logging"`
 	DefaultInterval int          `json:"default_interval"` // 默认上报间隔
+	StateDir    string           `json:"state_dir"`        // 模拟状态保存目录，为空时不保存
 }
 
 // MQTTGlobalConfig MQTT全局配置
@@ -172,6 +174,11 @@ func (di *DeviceInfo) GetUploadInterval(defaultInterval int) int {
 	return 30 // 默认30秒
 }
 
+// GetStateDir 获取模拟状态保存目录，为空表示不保存模拟状态
+func (gc *GlobalConfig) GetStateDir() string {
+	return gc.StateDir
+}
+
 // LoadDeviceTemplate 加载设备模板
 func LoadDeviceTemplate(templatePath string) (*DeviceTemplate, error) {
 	configFile := filepath.Join(templatePath, "template.json")
@@ -10,11 +10,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
 	"znb/iot-uplink-gen/manager"
+	"znb/iot-uplink-gen/simulator"
 )
 
 // ProcessStatus 进程状态
@@ -71,6 +73,7 @@ type ProcessManager struct {
 	cancel        context.CancelFunc
 	mutex         sync.RWMutex
 	running       bool
+	freshStart    bool // 启动时清除已保存的模拟状态
 	
 	// 监控
 	eventCh       chan ProcessEvent
@@ -108,6 +111,11 @@ func NewProcessManager(executablePath, workDir string) *ProcessManager {
 	}
 }
 
+// SetFreshStart 设置是否全新启动，需在Start前调用
+func (pm *ProcessManager) SetFreshStart(fresh bool) {
+	pm.freshStart = fresh
+}
+
 // LoadConfig 加载配置
 func (pm *ProcessManager) LoadConfig(configPath, templatePath string) error {
 	pm.mutex.Lock()
@@ -175,6 +183,13 @@ func (pm *ProcessManager) Start() error {
 
 	log.Println("启动进程管理器...")
 
+	// 全新启动时清除已保存的模拟状态
+	if pm.freshStart {
+		if err := simulator.ClearStateDir(pm.config.GlobalConfig.GetStateDir()); err != nil {
+			log.Printf("清除模拟状态失败: %v", err)
+		}
+	}
+
 	// 启动进程监控
 	go pm.processMonitor()
 
@@ -274,11 +289,16 @@ func (pm *ProcessManager) startDeviceProcess(deviceInfo *manager.DeviceInfo) err
 
 	// 构建命令行参数
 	logFile := filepath.Join(pm.logDir, fmt.Sprintf("%s.log", deviceInfo.DeviceID))
-	cmd := exec.CommandContext(ctx, pm.executablePath,
+	args := []string{
 		"-mode", "simulator",
 		"-product", template.ProductType,
 		"-config", processConfigFile,
-	)
+		"-state-dir", pm.config.GlobalConfig.GetStateDir(),
+	}
+	if deviceInfo.Seed != nil {
+		args = append(args, "-seed", strconv.FormatInt(*deviceInfo.Seed, 10))
+	}
+	cmd := exec.CommandContext(ctx, pm.executablePath, args...)
 	
 	// 设置工作目录
 	cmd.Dir = pm.workDir
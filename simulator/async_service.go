@@ -0,0 +1,107 @@
+package simulator
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"znb/iot-uplink-gen/tsl"
+)
+
+// asyncResultEvent 异步服务上报结果的事件标识符，默认为 服务标识符_result
+func asyncResultEvent(identifier string, async AsyncServiceConfig) string {
+	if async.ResultEvent != "" {
+		return async.ResultEvent
+	}
+	return identifier + "_result"
+}
+
+// validateAsyncServiceEvents 检查异步服务的结果和进度事件在TSL中定义，且事件参数都有取值来源
+func validateAsyncServiceEvents(identifier string, config ServiceSimConfig, events map[string]tsl.Event, properties map[string]PropertySimConfig) error {
+	var async AsyncServiceConfig
+	if config.Async != nil {
+		async = *config.Async
+	}
+
+	resultEvent := asyncResultEvent(identifier, async)
+	event, exists := events[resultEvent]
+	if !exists {
+		return fmt.Errorf("异步服务[%s]的结果事件[%s]在TSL中未定义", identifier, resultEvent)
+	}
+	provided := map[string]bool{"service": true, "code": true, "msg": true, "data": true}
+	if err := validateEventParamSources(event, nil, provided, properties); err != nil {
+		return err
+	}
+
+	if async.ProgressEvent == "" {
+		return nil
+	}
+	event, exists = events[async.ProgressEvent]
+	if !exists {
+		return fmt.Errorf("异步服务[%s]的进度事件[%s]在TSL中未定义", identifier, async.ProgressEvent)
+	}
+	provided = map[string]bool{"service": true, "progress": true}
+	return validateEventParamSources(event, nil, provided, properties)
+}
+
+// runAsyncService 延时后执行异步服务并通过事件上报结果，处理期间按间隔上报进度
+func (sd *SimulatedDevice) runAsyncService(identifier string, action *tsl.Action, config ServiceSimConfig, params map[string]interface{}, latency time.Duration) {
+	var async AsyncServiceConfig
+	if config.Async != nil {
+		async = *config.Async
+	}
+	resultEvent := asyncResultEvent(identifier, async)
+
+	var progress <-chan time.Time
+	if async.ProgressEvent != "" && async.ProgressInterval > 0 {
+		ticker := time.NewTicker(time.Duration(async.ProgressInterval) * time.Second)
+		defer ticker.Stop()
+		progress = ticker.C
+	}
+
+	start := time.Now()
+	done := time.After(latency)
+	for {
+		select {
+		case <-sd.stopCh:
+			sd.log(fmt.Sprintf("[%s] 模拟器已停止，异步服务[%s]未上报结果", sd.DeviceInfo.DeviceName, identifier))
+			return
+
+		case <-progress:
+			percent := 99
+			if elapsed := time.Since(start); elapsed < latency {
+				percent = int(elapsed * 100 / latency)
+			}
+			sd.reportServiceEvent(async.ProgressEvent, map[string]interface{}{
+				"service":  identifier,
+				"progress": percent,
+			})
+
+		case <-done:
+			sd.recordServiceLatency(latency)
+			payload, response := sd.executeService(identifier, action, config, params)
+			sd.reportServiceEvent(resultEvent, map[string]interface{}{
+				"service": identifier,
+				"code":    response.Code,
+				"msg":     response.Msg,
+				"data":    payload,
+			})
+			return
+		}
+	}
+}
+
+// reportServiceEvent 上报异步服务的结果或进度事件
+func (sd *SimulatedDevice) reportServiceEvent(identifier string, value map[string]interface{}) {
+	sd.simMutex.Lock()
+	eventPayload := sd.buildEventData(identifier, nil, value, sd.propertySim.GetLastValue)
+	sd.simMutex.Unlock()
+
+	if err := sd.framework.ReportEvent(identifier, eventPayload); err != nil {
+		sd.log(fmt.Sprintf("[%s] 发布事件[%s]失败: %v", sd.DeviceInfo.DeviceName, identifier, err))
+		atomic.AddInt64(&sd.stats.Errors, 1)
+		return
+	}
+	sd.log(fmt.Sprintf("[%s] 事件[%s]已上报: %v", sd.DeviceInfo.DeviceName, identifier, value))
+	atomic.AddInt64(&sd.stats.EventTriggers, 1)
+}
@@ -37,6 +37,11 @@ func (df *DeviceFactory) CreateDevice(productKey, deviceName, deviceSecret, prod
 		return nil, fmt.Errorf("TSL验证失败: %v", err)
 	}
 
+	// 校验设备与TSL所属产品一致
+	if err := validateProductKey(tslModel, productKey); err != nil {
+		return nil, err
+	}
+
 	// 加载规则文件
 	ruleFile := GenerateRuleFileName(productType)
 	rule, err := df.ruleManager.LoadRule(ruleFile)
@@ -57,6 +62,11 @@ func (df *DeviceFactory) CreateDevice(productKey, deviceName, deviceSecret, prod
 	// 创建模拟设备
 	device := NewSimulatedDevice(productKey, deviceName, deviceSecret, tslModel, rule)
 
+	// 加载回放数据
+	if err := df.loadReplayTraces(device, rule); err != nil {
+		return nil, fmt.Errorf("加载回放数据失败: %v", err)
+	}
+
 	return device, nil
 }
 
@@ -73,6 +83,11 @@ func (df *DeviceFactory) CreateDeviceFromFiles(productKey, deviceName, deviceSec
 		return nil, fmt.Errorf("TSL验证失败: %v", err)
 	}
 
+	// 校验设备与TSL所属产品一致
+	if err := validateProductKey(tslModel, productKey); err != nil {
+		return nil, err
+	}
+
 	// 加载规则文件
 	rule, err := df.ruleManager.LoadRule(ruleFile)
 	if err != nil {
@@ -92,6 +107,11 @@ func (df *DeviceFactory) CreateDeviceFromFiles(productKey, deviceName, deviceSec
 	// 创建模拟设备
 	device := NewSimulatedDevice(productKey, deviceName, deviceSecret, tslModel, rule)
 
+	// 加载回放数据
+	if err := df.loadReplayTraces(device, rule); err != nil {
+		return nil, fmt.Errorf("加载回放数据失败: %v", err)
+	}
+
 	return device, nil
 }
 
@@ -158,11 +178,15 @@ func (df *DeviceFactory) GenerateEmptyRule(tslFile string) (*SimulationRule, err
 
 // generateDefaultPropertyConfig 生成默认属性配置
 func (df *DeviceFactory) generateDefaultPropertyConfig(prop tsl.Property) PropertySimConfig {
+	return df.generateDefaultDataTypeConfig(prop.GetDataType())
+}
+
+// generateDefaultDataTypeConfig 按数据类型生成默认模拟配置，struct和array递归生成成员配置
+func (df *DeviceFactory) generateDefaultDataTypeConfig(dataType tsl.DataType) PropertySimConfig {
 	config := PropertySimConfig{
 		Method: "randomRange", // 默认使用随机范围
 	}
 
-	dataType := prop.GetDataType()
 	switch dataType.Type {
 	case "float", "double":
 		if dataType.Specs.Min != 0 || dataType.Specs.Max != 0 {
@@ -196,19 +220,47 @@ func (df *DeviceFactory) generateDefaultPropertyConfig(prop tsl.Property) Proper
 
 	case "enum":
 		config.Method = "enum"
-		if dataType.Specs.Enum != "" {
-			// 解析枚举值
-			// 这里需要根据实际的枚举值格式进行解析
-			config.EnumValues = []string{dataType.Specs.Enum}
+		if keys := dataType.Specs.GetEnumKeys(); len(keys) > 0 {
+			config.EnumValues = keys
 		} else {
 			config.EnumValues = []string{"enum1", "enum2", "enum3"}
 		}
 		config.SwitchProbability = 0.3
+
+	case "date":
+		config.Method = "timestamp"
+
+	case "struct":
+		config.Method = "struct"
+		config.Members = make(map[string]PropertySimConfig, len(dataType.Members))
+		for _, member := range dataType.Members {
+			config.Members[member.Identifier] = df.generateDefaultDataTypeConfig(member.GetDataType())
+		}
+
+	case "array":
+		config.Method = "array"
+		config.Size = dataType.Specs.Size
+		if config.Size <= 0 {
+			config.Size = 1
+		}
+		item := PropertySimConfig{Method: "fixed", Value: json.Number("0")}
+		if dataType.Specs.Item != nil {
+			item = df.generateDefaultDataTypeConfig(*dataType.Specs.Item)
+		}
+		config.Item = &item
 	}
 
 	return config
 }
 
+// validateProductKey 校验设备的ProductKey与TSL中声明的一致，TSL未声明时不校验
+func validateProductKey(tslModel *tsl.TSLModel, productKey string) error {
+	if tslProductKey := tslModel.GetProductKey(); tslProductKey != "" && tslProductKey != productKey {
+		return fmt.Errorf("设备ProductKey[%s]与TSL中的ProductKey[%s]不一致", productKey, tslProductKey)
+	}
+	return nil
+}
+
 // validateTSLRuleConsistency 验证TSL和规则的一致性
 func (df *DeviceFactory) validateTSLRuleConsistency(tslModel *tsl.TSLModel, rule *SimulationRule) error {
 	// 检查属性一致性
@@ -222,6 +274,9 @@ func (df *DeviceFactory) validateTSLRuleConsistency(tslModel *tsl.TSLModel, rule
 	tslProps := make(map[string]bool)
 	for _, prop := range tslModel.Properties {
 		tslProps[prop.Identifier] = true
+		if err := validateNoiseDataType(prop.Identifier, rule.SimulationConfig[prop.Identifier], prop.GetDataType()); err != nil {
+			return err
+		}
 	}
 
 	for identifier := range rule.SimulationConfig {
@@ -231,15 +286,30 @@ func (df *DeviceFactory) validateTSLRuleConsistency(tslModel *tsl.TSLModel, rule
 	}
 
 	// 检查事件一致性
-	tslEvents := make(map[string]bool)
+	tslEvents := make(map[string]tsl.Event)
 	for _, event := range tslModel.Events {
-		tslEvents[event.Identifier] = true
+		tslEvents[event.Identifier] = event
 	}
 
 	for _, eventConfig := range rule.Events {
-		if !tslEvents[eventConfig.Identifier] {
+		event, exists := tslEvents[eventConfig.Identifier]
+		if !exists {
 			return fmt.Errorf("事件[%s]在规则中配置但TSL中未定义", eventConfig.Identifier)
 		}
+		if err := validateEventParamSources(event, eventConfig.Params, nil, rule.SimulationConfig); err != nil {
+			return err
+		}
+
+		// 恢复事件没有参数规则，mtbf随机事件修复时提供downtime
+		if recovery, exists := tslEvents[eventConfig.RecoveryEvent]; exists {
+			var provided map[string]bool
+			if eventConfig.Stochastic != nil {
+				provided = map[string]bool{"downtime": true}
+			}
+			if err := validateEventParamSources(recovery, nil, provided, rule.SimulationConfig); err != nil {
+				return err
+			}
+		}
 	}
 
 	// 检查服务一致性
@@ -254,6 +324,77 @@ func (df *DeviceFactory) validateTSLRuleConsistency(tslModel *tsl.TSLModel, rule
 		}
 	}
 
+	// 异步服务通过事件上报结果和进度
+	for _, action := range tslModel.Actions {
+		service, exists := rule.Services[action.Identifier]
+		if !exists || action.CallType != "async" {
+			continue
+		}
+		if err := validateAsyncServiceEvents(action.Identifier, service, tslEvents, rule.SimulationConfig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateNoiseDataType 检查配置了noise的属性在TSL中是数值类型，递归检查struct成员和array元素
+func validateNoiseDataType(identifier string, config PropertySimConfig, dataType tsl.DataType) error {
+	switch dataType.Type {
+	case "bool", "enum", "text", "string", "date":
+		if config.Noise != nil {
+			return fmt.Errorf("属性[%s]在TSL中为%s类型，不能配置noise", identifier, dataType.Type)
+		}
+	case "struct":
+		for _, member := range dataType.Members {
+			if memberConfig, exists := config.Members[member.Identifier]; exists {
+				if err := validateNoiseDataType(identifier+"."+member.Identifier, memberConfig, member.GetDataType()); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		if config.Item != nil && dataType.Specs.Item != nil {
+			return validateNoiseDataType(identifier+"[]", *config.Item, *dataType.Specs.Item)
+		}
+	}
+	return nil
+}
+
+// loadReplayTraces 为使用replay方法的属性加载回放文件
+func (df *DeviceFactory) loadReplayTraces(device *SimulatedDevice, rule *SimulationRule) error {
+	for identifier, config := range rule.SimulationConfig {
+		if err := df.loadPropertyReplayTrace(device, rule, identifier, config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPropertyReplayTrace 加载单个属性的回放数据，递归处理struct成员和array元素
+func (df *DeviceFactory) loadPropertyReplayTrace(device *SimulatedDevice, rule *SimulationRule, identifier string, config PropertySimConfig) error {
+	switch config.Method {
+	case "replay":
+		if config.Replay == nil {
+			return nil
+		}
+		path := rule.ResolvePath(config.Replay.File)
+		if err := device.propertySim.LoadReplayTrace(identifier, path, *config.Replay); err != nil {
+			return fmt.Errorf("属性[%s]: %v", identifier, err)
+		}
+	case "struct":
+		for name, member := range config.Members {
+			if err := df.loadPropertyReplayTrace(device, rule, identifier+"."+name, member); err != nil {
+				return err
+			}
+		}
+	case "array":
+		for i := 0; i < config.Size; i++ {
+			if err := df.loadPropertyReplayTrace(device, rule, fmt.Sprintf("%s.%d", identifier, i), *config.Item); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
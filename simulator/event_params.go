@@ -0,0 +1,139 @@
+package simulator
+
+import (
+	"fmt"
+	"strings"
+
+	"znb/iot-uplink-gen/tsl"
+)
+
+// validateEventParamRule 验证事件输出参数的取值规则，引用的属性必须已配置
+func (m *RuleManager) validateEventParamRule(rule EventParamRule, properties map[string]PropertySimConfig) error {
+	switch rule.Method {
+	case "property":
+		if rule.Property == "" {
+			return fmt.Errorf("property方法需要property参数")
+		}
+		if _, exists := properties[strings.SplitN(rule.Property, ".", 2)[0]]; !exists {
+			return fmt.Errorf("引用了未配置的属性: %s", rule.Property)
+		}
+	case "constant":
+		if rule.Value == nil {
+			return fmt.Errorf("constant方法需要value参数")
+		}
+	case "expression":
+		if rule.Expression == "" {
+			return fmt.Errorf("expression方法需要expression参数")
+		}
+		if err := validateTriggerCondition(rule.Expression, properties); err != nil {
+			return fmt.Errorf("表达式无效: %v", err)
+		}
+		if expr, _ := CompileExpression(rule.Expression); len(expr.HistoryWindows()) > 0 {
+			return fmt.Errorf("参数表达式不支持delta/slope/avg等趋势函数")
+		}
+	case "generator":
+		if rule.Generator == nil {
+			return fmt.Errorf("generator方法需要generator参数")
+		}
+		if rule.Generator.Method == "replay" {
+			return fmt.Errorf("generator不支持replay方法")
+		}
+		if err := m.validatePropertyConfig(*rule.Generator); err != nil {
+			return fmt.Errorf("generator配置无效: %v", err)
+		}
+	default:
+		return fmt.Errorf("不支持的参数取值方法: %s", rule.Method)
+	}
+	return nil
+}
+
+// validateEventParamSources 检查事件在TSL中定义的每个输出参数都有取值来源：
+// 参数规则、上报时提供的值(provided)或规则中配置的同名属性
+func validateEventParamSources(event tsl.Event, rules map[string]EventParamRule, provided map[string]bool, properties map[string]PropertySimConfig) error {
+	for _, output := range event.GetOutputData() {
+		if _, exists := rules[output.Identifier]; exists {
+			continue
+		}
+		if _, exists := properties[output.Identifier]; exists {
+			continue
+		}
+		if provided[output.Identifier] {
+			continue
+		}
+		return fmt.Errorf("事件[%s]的输出参数[%s]没有取值来源，请在params中配置或配置同名属性", event.Identifier, output.Identifier)
+	}
+	return nil
+}
+
+// findEvent 查找TSL中定义的事件
+func (sd *SimulatedDevice) findEvent(identifier string) *tsl.Event {
+	for i := range sd.tslModel.Events {
+		if sd.tslModel.Events[i].Identifier == identifier {
+			return &sd.tslModel.Events[i]
+		}
+	}
+	return nil
+}
+
+// buildEventData 按TSL中事件的输出参数定义构造事件参数并按参数的数据类型转换。
+// 未配置规则的参数依次取values和属性中的同名值，都没有时记录日志并省略该参数，
+// TSL未定义的事件沿用{identifier: {value, time}}格式。调用方需持有simMutex
+func (sd *SimulatedDevice) buildEventData(identifier string, rules map[string]EventParamRule, values map[string]interface{}, resolve exprResolver) map[string]interface{} {
+	event := sd.findEvent(identifier)
+	if event == nil {
+		return buildEventPayload(identifier, values)
+	}
+
+	outputs := event.GetOutputData()
+	data := make(map[string]interface{}, len(outputs))
+	for _, output := range outputs {
+		dataType := output.GetDataType()
+
+		var value interface{}
+		if rule, exists := rules[output.Identifier]; exists {
+			value = sd.eventParamValue(identifier, output.Identifier, rule, resolve)
+		} else if current, exists := values[output.Identifier]; exists {
+			value = current
+		} else if current, exists := resolve(output.Identifier); exists {
+			value = current
+		}
+		if value == nil {
+			sd.log(fmt.Sprintf("[%s] 事件[%s]的参数[%s]没有可用的值", sd.DeviceInfo.DeviceName, identifier, output.Identifier))
+			continue
+		}
+
+		converted, err := sd.convertValue(value, dataType)
+		if err != nil {
+			sd.log(fmt.Sprintf("[%s] 事件[%s]的参数[%s]类型转换失败: %v", sd.DeviceInfo.DeviceName, identifier, output.Identifier, err))
+		}
+		data[output.Identifier] = converted
+	}
+	return data
+}
+
+// eventParamValue 按参数规则取值，调用方需持有simMutex
+func (sd *SimulatedDevice) eventParamValue(event, param string, rule EventParamRule, resolve exprResolver) interface{} {
+	switch rule.Method {
+	case "property":
+		value, _ := resolve(rule.Property)
+		return value
+	case "constant":
+		return rule.Value
+	case "expression":
+		expr, err := sd.eventSim.compileCondition(rule.Expression)
+		if err != nil {
+			sd.log(fmt.Sprintf("[%s] 事件[%s]的参数[%s]表达式无法解析: %v", sd.DeviceInfo.DeviceName, event, param, err))
+			return nil
+		}
+		value, err := expr.Evaluate(resolve)
+		if err != nil {
+			sd.log(fmt.Sprintf("[%s] 事件[%s]的参数[%s]表达式计算失败: %v", sd.DeviceInfo.DeviceName, event, param, err))
+			return nil
+		}
+		return value
+	case "generator":
+		// 生成器的状态与属性分开保存
+		return sd.propertySim.SimulateValue("$event."+event+"."+param, *rule.Generator)
+	}
+	return nil
+}
@@ -1,9 +1,8 @@
 package simulator
 
 import (
-	"fmt"
 	"log"
-	"math"
+	"math/rand"
 	"strconv"
 	"strings"
 	"time"
@@ -11,45 +10,184 @@ import (
 
 // EventSimulator 事件模拟器
 type EventSimulator struct {
-	lastTriggerTime map[string]int64 // 记录事件上次触发时间
+	lastTriggerTime map[string]int64               // 记录事件上次触发时间
+	conditions      map[string]*Expression         // 已编译的触发条件
+	pending         map[string]*pendingCondition   // 触发条件已满足但尚未达到持续要求的事件
+	alarms          map[string]*AlarmState         // 告警模式事件的告警状态
+	history         *propertyHistory               // 趋势函数引用的属性历史值
+	stochastic      map[string]*StochasticSchedule // 随机事件的调度状态
+	rng             *rand.Rand                     // 设备级随机数源
+}
+
+// pendingCondition 触发条件连续满足的起始时间和周期数
+type pendingCondition struct {
+	since  time.Time
+	cycles int
+}
+
+// AlarmState 事件的告警状态，配置了clearCondition或recoveryEvent的事件在恢复前不会重复触发
+type AlarmState struct {
+	Active     bool  `json:"active"`              // 是否处于告警中
+	RaisedAt   int64 `json:"raisedAt,omitempty"`  // 最近一次告警时间
+	ClearedAt  int64 `json:"clearedAt,omitempty"` // 最近一次恢复时间
+	RaiseCount int64 `json:"raiseCount"`          // 累计告警次数
 }
 
 // NewEventSimulator 创建事件模拟器
-func NewEventSimulator() *EventSimulator {
+func NewEventSimulator(rng *rand.Rand) *EventSimulator {
 	return &EventSimulator{
 		lastTriggerTime: make(map[string]int64),
+		conditions:      make(map[string]*Expression),
+		pending:         make(map[string]*pendingCondition),
+		alarms:          make(map[string]*AlarmState),
+		history:         newPropertyHistory(),
+		stochastic:      make(map[string]*StochasticSchedule),
+		rng:             rng,
 	}
 }
 
-// CheckEventTrigger 检查事件是否应该触发
+// PrepareConditions 预先编译事件的触发和恢复条件，并登记趋势函数需要记录的属性历史
+func (es *EventSimulator) PrepareConditions(events []EventSimConfig) {
+	for _, event := range events {
+		for _, condition := range []string{event.TriggerCondition, event.ClearCondition} {
+			if condition == "" {
+				continue
+			}
+			if _, err := es.compileCondition(condition); err != nil {
+				log.Printf("无法解析事件[%s]的条件: %s: %v", event.Identifier, condition, err)
+			}
+		}
+	}
+}
+
+// RecordHistory 记录趋势函数引用的属性的当前值，应在每个周期检查事件前调用
+func (es *EventSimulator) RecordHistory(propertyData map[string]interface{}) {
+	es.history.record(propertyResolver(propertyData), time.Now())
+}
+
+// isAlarmEvent 判断事件是否按告警方式触发和恢复
+func isAlarmEvent(config EventSimConfig) bool {
+	return config.ClearCondition != "" || config.RecoveryEvent != ""
+}
+
+// CheckEventTrigger 检查事件是否应该触发，触发条件需按sustain或sustainCycles持续满足，触发时同时返回条件引用的属性值
 func (es *EventSimulator) CheckEventTrigger(config EventSimConfig, propertyData map[string]interface{}) (bool, map[string]interface{}) {
+	// 检查触发条件
+	if config.TriggerCondition == "" {
+		return false, nil
+	}
+
+	// 告警中的事件等待恢复，不重复触发
+	if alarm, exists := es.alarms[config.Identifier]; exists && alarm.Active {
+		return false, nil
+	}
+
+	triggered, eventData := es.evaluateCondition(config.TriggerCondition, propertyData)
+	if !triggered {
+		delete(es.pending, config.Identifier)
+		return false, nil
+	}
+
+	now := time.Now()
+	pending, exists := es.pending[config.Identifier]
+	if !exists {
+		pending = &pendingCondition{since: now}
+		es.pending[config.Identifier] = pending
+	}
+	pending.cycles++
+	if !isSustained(config, pending, now) {
+		return false, nil
+	}
+
 	// 检查冷却时间
 	if !es.canTriggerEvent(config) {
 		return false, nil
 	}
 
-	// 检查触发条件
-	if config.TriggerCondition == "" {
+	// 更新最后触发时间
+	delete(es.pending, config.Identifier)
+	es.lastTriggerTime[config.Identifier] = now.Unix()
+	if isAlarmEvent(config) {
+		alarm := es.alarmState(config.Identifier)
+		alarm.Active = true
+		alarm.RaisedAt = now.Unix()
+		alarm.RaiseCount++
+	}
+
+	return true, eventData
+}
+
+// isSustained 判断触发条件是否已持续满足，同时配置sustain和sustainCycles时满足其中之一即可
+func isSustained(config EventSimConfig, pending *pendingCondition, now time.Time) bool {
+	if config.Sustain <= 0 && config.SustainCycles <= 0 {
+		return true
+	}
+	if config.Sustain > 0 && now.Sub(pending.since) >= time.Duration(config.Sustain)*time.Second {
+		return true
+	}
+	return config.SustainCycles > 0 && pending.cycles >= config.SustainCycles
+}
+
+// CheckEventRecovery 检查告警中的事件是否恢复，未配置clearCondition时触发条件不再满足即恢复。
+// 返回是否恢复以及恢复条件引用的属性值
+func (es *EventSimulator) CheckEventRecovery(config EventSimConfig, propertyData map[string]interface{}) (bool, map[string]interface{}) {
+	alarm, exists := es.alarms[config.Identifier]
+	if !exists || !alarm.Active {
 		return false, nil
 	}
 
-	triggered, eventData := es.evaluateCondition(config.TriggerCondition, propertyData)
-	if triggered {
-		// 更新最后触发时间
-		es.lastTriggerTime[config.Identifier] = time.Now().Unix()
-		
-		// 构造事件数据
-		eventPayload := map[string]interface{}{
-			config.Identifier: map[string]interface{}{
-				"value": eventData,
-				"time":  time.Now().Unix(),
-			},
-		}
-		
-		return true, eventPayload
+	var cleared bool
+	var eventData map[string]interface{}
+	if config.ClearCondition != "" {
+		cleared, eventData = es.evaluateCondition(config.ClearCondition, propertyData)
+	} else {
+		var triggered bool
+		triggered, eventData = es.evaluateCondition(config.TriggerCondition, propertyData)
+		// 条件无法评估时保持告警
+		cleared = !triggered && eventData != nil
+	}
+	if !cleared {
+		return false, nil
+	}
+
+	alarm.Active = false
+	alarm.ClearedAt = time.Now().Unix()
+	return true, eventData
+}
+
+// buildEventPayload 构造TSL未定义的事件的数据
+func buildEventPayload(identifier string, eventData map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		identifier: map[string]interface{}{
+			"value": eventData,
+			"time":  time.Now().Unix(),
+		},
+	}
+}
+
+// alarmState 获取事件的告警状态，不存在时创建
+func (es *EventSimulator) alarmState(identifier string) *AlarmState {
+	alarm, exists := es.alarms[identifier]
+	if !exists {
+		alarm = &AlarmState{}
+		es.alarms[identifier] = alarm
 	}
+	return alarm
+}
 
-	return false, nil
+// GetAlarmStates 获取所有告警模式事件的告警状态
+func (es *EventSimulator) GetAlarmStates() map[string]AlarmState {
+	states := make(map[string]AlarmState, len(es.alarms))
+	for identifier, alarm := range es.alarms {
+		states[identifier] = *alarm
+	}
+	return states
+}
+
+// SetAlarmState 设置事件的告警状态（用于状态恢复）
+func (es *EventSimulator) SetAlarmState(identifier string, state AlarmState) {
+	alarm := state
+	es.alarms[identifier] = &alarm
 }
 
 // canTriggerEvent 检查是否在冷却时间外
@@ -63,126 +201,88 @@ func (es *EventSimulator) canTriggerEvent(config EventSimConfig) bool {
 	return now-lastTime >= int64(config.Cooldown)
 }
 
-// evaluateCondition 评估触发条件
+// evaluateCondition 评估触发条件，返回条件引用的属性在评估时的值，无法评估时返回nil
 func (es *EventSimulator) evaluateCondition(condition string, propertyData map[string]interface{}) (bool, map[string]interface{}) {
-	// 解析条件，支持格式: "temperature > 30", "humidity <= 80", "status == online"
-	condition = strings.TrimSpace(condition)
-	if condition == "" {
+	expr, err := es.compileCondition(condition)
+	if err != nil {
+		log.Printf("无法解析触发条件: %s: %v", condition, err)
 		return false, nil
 	}
 
-	// 简单解析条件
-	operators := []string{">=", "<=", "==", "!=", ">", "<"}
-	var prop, op, value string
-	
-	for _, operator := range operators {
-		if strings.Contains(condition, operator) {
-			parts := strings.Split(condition, operator)
-			if len(parts) == 2 {
-				prop = strings.TrimSpace(parts[0])
-				op = operator
-				value = strings.TrimSpace(parts[1])
-				break
-			}
-		}
-	}
-	
-	if prop == "" || op == "" || value == "" {
-		log.Printf("无法解析触发条件: %s", condition)
+	resolve := propertyResolver(propertyData)
+	triggered, err := expr.EvaluateBoolWithHistory(resolve, es.history)
+	if err != nil {
+		log.Printf("评估触发条件[%s]失败: %v", condition, err)
 		return false, nil
 	}
 
-	// 获取属性值
-	propValue, exists := propertyData[prop]
-	if !exists {
-		log.Printf("属性 %s 不存在于当前数据中", prop)
-		return false, nil
+	// 返回评估时的属性值
+	eventData := make(map[string]interface{}, len(expr.Identifiers()))
+	for _, name := range expr.Identifiers() {
+		if value, exists := resolve(name); exists {
+			eventData[name] = value
+		}
 	}
+	return triggered, eventData
+}
 
-	// 提取实际值
-	var actualValue interface{}
-	if propMap, ok := propValue.(map[string]interface{}); ok {
-		actualValue = propMap["value"]
-	} else {
-		actualValue = propValue
+// compileCondition 编译并缓存触发条件
+func (es *EventSimulator) compileCondition(condition string) (*Expression, error) {
+	if expr, exists := es.conditions[condition]; exists {
+		return expr, nil
+	}
+	expr, err := CompileExpression(condition)
+	if err != nil {
+		return nil, err
 	}
+	es.conditions[condition] = expr
+	es.history.track(expr.HistoryWindows())
+	return expr, nil
+}
 
-	// 进行比较
-	result := es.compareValues(actualValue, op, value)
-	
-	if result {
-		// 返回触发时的属性值
-		eventData := map[string]interface{}{
-			prop: actualValue,
+// propertyResolver 创建从上报数据中获取属性值的解析函数
+func propertyResolver(propertyData map[string]interface{}) exprResolver {
+	return func(name string) (interface{}, bool) {
+		value, exists := lookupPropertyPath(propertyData, name)
+		if !exists {
+			return nil, false
 		}
-		return true, eventData
+		// 提取实际值
+		if propMap, ok := value.(map[string]interface{}); ok {
+			if actual, ok := propMap["value"]; ok {
+				return actual, true
+			}
+		}
+		return value, true
 	}
-
-	return false, nil
 }
 
-// compareValues 比较值
-func (es *EventSimulator) compareValues(actualValue interface{}, operator, expectedValue string) bool {
-	// 尝试数值比较
-	if actualFloat, actualOk := es.convertToFloat(actualValue); actualOk {
-		if expectedFloat, expectedOk := es.convertToFloat(expectedValue); expectedOk {
-			return es.compareFloat(actualFloat, operator, expectedFloat)
-		}
+// lookupPropertyPath 按路径获取属性值，支持struct成员(gps.lat)和array元素(channels.0)
+func lookupPropertyPath(propertyData map[string]interface{}, path string) (interface{}, bool) {
+	if value, exists := propertyData[path]; exists {
+		return value, true
 	}
 
-	// 字符串比较
-	actualStr := fmt.Sprintf("%v", actualValue)
-	expectedStr := strings.Trim(expectedValue, "\"'")
-	
-	switch operator {
-	case "==":
-		return actualStr == expectedStr
-	case "!=":
-		return actualStr != expectedStr
-	default:
-		log.Printf("字符串类型不支持操作符: %s", operator)
-		return false
-	}
-}
-
-// convertToFloat 尝试将值转换为float64
-func (es *EventSimulator) convertToFloat(value interface{}) (float64, bool) {
-	switch v := value.(type) {
-	case float64:
-		return v, true
-	case float32:
-		return float64(v), true
-	case int:
-		return float64(v), true
-	case int64:
-		return float64(v), true
-	case string:
-		if f, err := strconv.ParseFloat(v, 64); err == nil {
-			return f, true
+	var current interface{} = propertyData
+	for _, part := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, exists := v[part]
+			if !exists {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			current = v[idx]
+		default:
+			return nil, false
 		}
 	}
-	return 0, false
-}
-
-// compareFloat 比较float64值
-func (es *EventSimulator) compareFloat(actual float64, operator string, expected float64) bool {
-	switch operator {
-	case ">":
-		return actual > expected
-	case ">=":
-		return actual >= expected
-	case "<":
-		return actual < expected
-	case "<=":
-		return actual <= expected
-	case "==":
-		return math.Abs(actual-expected) < 1e-9 // 浮点数相等比较
-	case "!=":
-		return math.Abs(actual-expected) >= 1e-9
-	default:
-		log.Printf("不支持的操作符: %s", operator)
-		return false
-	}
+	return current, true
 }
 
 // GetCooldownStatus 获取事件冷却状态
@@ -0,0 +1,36 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsSustained(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		config  EventSimConfig
+		elapsed time.Duration
+		cycles  int
+		want    bool
+	}{
+		{name: "未配置持续条件", config: EventSimConfig{}, want: true},
+		{name: "持续时间已满足", config: EventSimConfig{Sustain: 10}, elapsed: 10 * time.Second, cycles: 1, want: true},
+		{name: "持续时间未满足", config: EventSimConfig{Sustain: 10}, elapsed: 9 * time.Second, cycles: 100, want: false},
+		{name: "周期数已满足", config: EventSimConfig{SustainCycles: 3}, cycles: 3, want: true},
+		{name: "周期数未满足", config: EventSimConfig{SustainCycles: 3}, elapsed: time.Hour, cycles: 2, want: false},
+		{name: "同时配置仅时间满足", config: EventSimConfig{Sustain: 10, SustainCycles: 3}, elapsed: 15 * time.Second, cycles: 1, want: true},
+		{name: "同时配置仅周期满足", config: EventSimConfig{Sustain: 10, SustainCycles: 3}, elapsed: time.Second, cycles: 3, want: true},
+		{name: "同时配置均未满足", config: EventSimConfig{Sustain: 10, SustainCycles: 3}, elapsed: 5 * time.Second, cycles: 2, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending := &pendingCondition{since: now.Add(-tt.elapsed), cycles: tt.cycles}
+			if got := isSustained(tt.config, pending, now); got != tt.want {
+				t.Fatalf("isSustained() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
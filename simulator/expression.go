@@ -0,0 +1,833 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Expression 已编译的表达式
+type Expression struct {
+	source         string
+	root           exprNode
+	identifiers    []string
+	historyWindows map[string]time.Duration // 趋势函数引用的属性及其最长时间窗口
+}
+
+// exprResolver 根据标识符获取变量值
+type exprResolver func(name string) (interface{}, bool)
+
+// exprHistory 提供属性在时间窗口内的历史样本，用于delta/slope/avg等趋势函数
+type exprHistory interface {
+	samples(name string, window time.Duration) []historySample
+}
+
+// exprEnv 表达式求值环境
+type exprEnv struct {
+	resolve exprResolver
+	history exprHistory // 为nil时趋势函数不可用
+}
+
+// exprNode 表达式语法树节点
+type exprNode interface {
+	eval(env *exprEnv) (interface{}, error)
+}
+
+// exprFunc 内置函数定义
+type exprFunc struct {
+	minArgs int
+	maxArgs int // -1 表示不限
+	call    func(args []float64) (float64, error)
+}
+
+// exprFunctions 表达式支持的内置函数
+var exprFunctions = map[string]exprFunc{
+	"abs":   {1, 1, func(a []float64) (float64, error) { return math.Abs(a[0]), nil }},
+	"sqrt":  {1, 1, func(a []float64) (float64, error) { return math.Sqrt(a[0]), nil }},
+	"round": {1, 1, func(a []float64) (float64, error) { return math.Round(a[0]), nil }},
+	"floor": {1, 1, func(a []float64) (float64, error) { return math.Floor(a[0]), nil }},
+	"ceil":  {1, 1, func(a []float64) (float64, error) { return math.Ceil(a[0]), nil }},
+	"pow":   {2, 2, func(a []float64) (float64, error) { return math.Pow(a[0], a[1]), nil }},
+	"min": {1, -1, func(a []float64) (float64, error) {
+		result := a[0]
+		for _, v := range a[1:] {
+			result = math.Min(result, v)
+		}
+		return result, nil
+	}},
+	"max": {1, -1, func(a []float64) (float64, error) {
+		result := a[0]
+		for _, v := range a[1:] {
+			result = math.Max(result, v)
+		}
+		return result, nil
+	}},
+}
+
+// historyFunctions 基于属性历史值的趋势函数
+var historyFunctions = map[string]bool{
+	"delta": true,
+	"slope": true,
+	"avg":   true,
+}
+
+// CompileExpression 编译表达式，语法错误在此阶段返回
+func CompileExpression(source string) (*Expression, error) {
+	tokens, err := tokenizeExpression(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("表达式在位置%d存在多余内容: %s", tok.pos, tok.text)
+	}
+
+	identifiers := make([]string, 0, len(p.identifiers))
+	for name := range p.identifiers {
+		identifiers = append(identifiers, name)
+	}
+	sort.Strings(identifiers)
+
+	return &Expression{
+		source:         source,
+		root:           root,
+		identifiers:    identifiers,
+		historyWindows: p.historyWindows,
+	}, nil
+}
+
+// Evaluate 使用给定的变量解析函数计算表达式
+func (e *Expression) Evaluate(resolve exprResolver) (interface{}, error) {
+	return e.root.eval(&exprEnv{resolve: resolve})
+}
+
+// EvaluateBool 计算表达式并转换为布尔结果，数值非0视为true，用于条件判断
+func (e *Expression) EvaluateBool(resolve exprResolver) (bool, error) {
+	return evalBool(e.root, &exprEnv{resolve: resolve})
+}
+
+// EvaluateBoolWithHistory 计算引用趋势函数的条件表达式
+func (e *Expression) EvaluateBoolWithHistory(resolve exprResolver, history exprHistory) (bool, error) {
+	return evalBool(e.root, &exprEnv{resolve: resolve, history: history})
+}
+
+// EvaluateFloat 计算表达式并转换为数值结果
+func (e *Expression) EvaluateFloat(resolve exprResolver) (float64, error) {
+	value, err := e.Evaluate(resolve)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := toFloat64(value)
+	if !ok {
+		return 0, fmt.Errorf("表达式结果不是数值: %v", value)
+	}
+	return f, nil
+}
+
+// Identifiers 返回表达式引用的所有变量标识符
+func (e *Expression) Identifiers() []string {
+	return e.identifiers
+}
+
+// HistoryWindows 返回趋势函数引用的属性及其需要保留的最长时间窗口
+func (e *Expression) HistoryWindows() map[string]time.Duration {
+	return e.historyWindows
+}
+
+// String 返回表达式源文本
+func (e *Expression) String() string {
+	return e.source
+}
+
+// ---- 词法分析 ----
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenDuration
+	tokenString
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenizeExpression 将表达式拆分为词法单元
+func tokenizeExpression(source string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// 科学计数法
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					i = j
+					for i < len(runes) && unicode.IsDigit(runes[i]) {
+						i++
+					}
+				}
+			}
+			// 时长字面量，如60s、5m、1h
+			if i < len(runes) && strings.ContainsRune("smh", runes[i]) && (i+1 >= len(runes) || !isIdentRune(runes[i+1])) {
+				i++
+				tokens = append(tokens, exprToken{kind: tokenDuration, text: string(runes[start:i]), pos: start})
+				break
+			}
+			tokens = append(tokens, exprToken{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+
+		case r == '(':
+			tokens = append(tokens, exprToken{kind: tokenLParen, text: "(", pos: i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, exprToken{kind: tokenRParen, text: ")", pos: i})
+			i++
+
+		case r == ',':
+			tokens = append(tokens, exprToken{kind: tokenComma, text: ",", pos: i})
+			i++
+
+		case r == '\'' || r == '"':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != r {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("表达式在位置%d的字符串缺少结束引号", start)
+			}
+			tokens = append(tokens, exprToken{kind: tokenString, text: string(runes[start+1 : i]), pos: start})
+			i++
+
+		case strings.ContainsRune("=!<>", r):
+			// 比较运算符和逻辑非
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, exprToken{kind: tokenOperator, text: string(runes[i : i+2]), pos: i})
+				i += 2
+				continue
+			}
+			if r == '=' {
+				return nil, fmt.Errorf("表达式在位置%d存在无法识别的运算符: %c", i, r)
+			}
+			tokens = append(tokens, exprToken{kind: tokenOperator, text: string(r), pos: i})
+			i++
+
+		case r == '&' || r == '|':
+			// 逻辑与、逻辑或
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("表达式在位置%d存在无法识别的运算符: %c", i, r)
+			}
+			tokens = append(tokens, exprToken{kind: tokenOperator, text: string(runes[i : i+2]), pos: i})
+			i += 2
+
+		case strings.ContainsRune("+-*/%", r):
+			tokens = append(tokens, exprToken{kind: tokenOperator, text: string(r), pos: i})
+			i++
+
+		default:
+			return nil, fmt.Errorf("表达式在位置%d存在无法识别的字符: %c", i, r)
+		}
+	}
+
+	tokens = append(tokens, exprToken{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// isIdentRune 判断字符是否可以出现在标识符中（首字符之后），功能模块的属性形如 模块ID:标识符
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == ':'
+}
+
+// ---- 语法分析 ----
+
+type exprParser struct {
+	tokens         []exprToken
+	pos            int
+	identifiers    map[string]bool
+	historyWindows map[string]time.Duration
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// matchOperator 当前为指定运算符之一时消费并返回
+func (p *exprParser) matchOperator(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.next()
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// parseExpression 表达式入口
+func (p *exprParser) parseExpression() (exprNode, error) {
+	return p.parseOr()
+}
+
+// parseOr 解析逻辑或
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.matchOperator("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+}
+
+// parseAnd 解析逻辑与
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.matchOperator("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+}
+
+// parseComparison 解析比较运算
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.matchOperator("==", "!=", ">=", "<=", ">", "<")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{op: op, left: left, right: right}, nil
+}
+
+// parseAdditive 解析加减
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.matchOperator("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parseMultiplicative 解析乘除取模
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.matchOperator("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parseUnary 解析一元运算
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.matchOperator("-", "+", "!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary 解析数字、变量、函数调用和括号
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("表达式在位置%d存在无效数字: %s", tok.pos, tok.text)
+		}
+		return &literalNode{value: value}, nil
+
+	case tokenString:
+		return &literalNode{value: tok.text}, nil
+
+	case tokenDuration:
+		d, err := time.ParseDuration(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("表达式在位置%d存在无效时长: %s", tok.pos, tok.text)
+		}
+		return &literalNode{value: d.Seconds()}, nil
+
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		}
+		if p.identifiers == nil {
+			p.identifiers = make(map[string]bool)
+		}
+		p.identifiers[tok.text] = true
+		return &identNode{name: tok.text}, nil
+
+	case tokenLParen:
+		inner, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("表达式在位置%d缺少右括号", closing.pos)
+		}
+		return inner, nil
+
+	case tokenEOF:
+		return nil, fmt.Errorf("表达式意外结束")
+
+	default:
+		return nil, fmt.Errorf("表达式在位置%d存在意外的符号: %s", tok.pos, tok.text)
+	}
+}
+
+// parseCall 解析函数调用
+func (p *exprParser) parseCall(name exprToken) (exprNode, error) {
+	if historyFunctions[name.text] {
+		return p.parseHistoryCall(name)
+	}
+
+	fn, exists := exprFunctions[name.text]
+	if !exists {
+		return nil, fmt.Errorf("不支持的函数: %s", name.text)
+	}
+	p.next() // (
+
+	var args []exprNode
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if closing := p.next(); closing.kind != tokenRParen {
+		return nil, fmt.Errorf("函数%s在位置%d缺少右括号", name.text, closing.pos)
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("函数%s的参数个数无效: %d", name.text, len(args))
+	}
+	return &callNode{name: name.text, fn: fn, args: args}, nil
+}
+
+// parseHistoryCall 解析趋势函数调用，格式为 fn(属性标识符, 时间窗口)，时间窗口必须是常量
+func (p *exprParser) parseHistoryCall(name exprToken) (exprNode, error) {
+	p.next() // (
+
+	ident := p.next()
+	if ident.kind != tokenIdent {
+		return nil, fmt.Errorf("函数%s的第一个参数必须是属性标识符", name.text)
+	}
+	if comma := p.next(); comma.kind != tokenComma {
+		return nil, fmt.Errorf("函数%s缺少时间窗口参数", name.text)
+	}
+
+	var window time.Duration
+	switch tok := p.next(); tok.kind {
+	case tokenDuration:
+		window, _ = time.ParseDuration(tok.text)
+	case tokenNumber:
+		seconds, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("表达式在位置%d存在无效数字: %s", tok.pos, tok.text)
+		}
+		window = time.Duration(seconds * float64(time.Second))
+	default:
+		return nil, fmt.Errorf("函数%s的时间窗口必须是常量，如60s、5m", name.text)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("函数%s的时间窗口必须大于0", name.text)
+	}
+
+	if closing := p.next(); closing.kind != tokenRParen {
+		return nil, fmt.Errorf("函数%s在位置%d缺少右括号", name.text, closing.pos)
+	}
+
+	if p.identifiers == nil {
+		p.identifiers = make(map[string]bool)
+	}
+	p.identifiers[ident.text] = true
+	if p.historyWindows == nil {
+		p.historyWindows = make(map[string]time.Duration)
+	}
+	if window > p.historyWindows[ident.text] {
+		p.historyWindows[ident.text] = window
+	}
+	return &historyNode{fn: name.text, name: ident.text, window: window}, nil
+}
+
+// ---- 语法树节点 ----
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(env *exprEnv) (interface{}, error) {
+	return n.value, nil
+}
+
+type identNode struct {
+	name string
+}
+
+func (n *identNode) eval(env *exprEnv) (interface{}, error) {
+	value, exists := env.resolve(n.name)
+	if !exists {
+		return nil, fmt.Errorf("变量%s不存在", n.name)
+	}
+	return value, nil
+}
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+func (n *unaryNode) eval(env *exprEnv) (interface{}, error) {
+	if n.op == "!" {
+		value, err := evalBool(n.operand, env)
+		if err != nil {
+			return nil, err
+		}
+		return !value, nil
+	}
+
+	value, err := evalFloat(n.operand, env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "-" {
+		return -value, nil
+	}
+	return value, nil
+}
+
+type binaryNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+func (n *binaryNode) eval(env *exprEnv) (interface{}, error) {
+	left, err := evalFloat(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := evalFloat(n.right, env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		if right == 0 {
+			return nil, fmt.Errorf("除数为0")
+		}
+		return left / right, nil
+	case "%":
+		if right == 0 {
+			return nil, fmt.Errorf("除数为0")
+		}
+		return math.Mod(left, right), nil
+	}
+	return nil, fmt.Errorf("不支持的运算符: %s", n.op)
+}
+
+type logicalNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+// eval 短路求值，右侧仅在需要时计算
+func (n *logicalNode) eval(env *exprEnv) (interface{}, error) {
+	left, err := evalBool(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	if (n.op == "&&" && !left) || (n.op == "||" && left) {
+		return left, nil
+	}
+	return evalBool(n.right, env)
+}
+
+type compareNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+// eval 两侧均为数值时按数值比较，否则按字符串比较（仅支持==和!=）
+func (n *compareNode) eval(env *exprEnv) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	leftFloat, leftOk := compareOperand(left)
+	rightFloat, rightOk := compareOperand(right)
+	if leftOk && rightOk {
+		switch n.op {
+		case "==":
+			return math.Abs(leftFloat-rightFloat) < 1e-9, nil // 浮点数相等比较
+		case "!=":
+			return math.Abs(leftFloat-rightFloat) >= 1e-9, nil
+		case ">":
+			return leftFloat > rightFloat, nil
+		case ">=":
+			return leftFloat >= rightFloat, nil
+		case "<":
+			return leftFloat < rightFloat, nil
+		case "<=":
+			return leftFloat <= rightFloat, nil
+		}
+	}
+
+	leftStr, rightStr := fmt.Sprintf("%v", left), fmt.Sprintf("%v", right)
+	switch n.op {
+	case "==":
+		return leftStr == rightStr, nil
+	case "!=":
+		return leftStr != rightStr, nil
+	}
+	return nil, fmt.Errorf("字符串不支持运算符: %s", n.op)
+}
+
+// historyNode 趋势函数节点：delta为窗口内的变化量，slope为每分钟的变化率(最小二乘)，avg为窗口内的平均值
+type historyNode struct {
+	fn     string
+	name   string
+	window time.Duration
+}
+
+func (n *historyNode) eval(env *exprEnv) (interface{}, error) {
+	if env.history == nil {
+		return nil, fmt.Errorf("函数%s需要属性历史数据", n.fn)
+	}
+	samples := env.history.samples(n.name, n.window)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("属性%s没有历史数据", n.name)
+	}
+
+	switch n.fn {
+	case "delta":
+		return samples[len(samples)-1].value - samples[0].value, nil
+
+	case "avg":
+		sum := 0.0
+		for _, sample := range samples {
+			sum += sample.value
+		}
+		return sum / float64(len(samples)), nil
+
+	case "slope":
+		// 以首个样本为时间原点做最小二乘拟合
+		var sumT, sumV, sumTT, sumTV float64
+		for _, sample := range samples {
+			t := sample.at.Sub(samples[0].at).Minutes()
+			sumT += t
+			sumV += sample.value
+			sumTT += t * t
+			sumTV += t * sample.value
+		}
+		count := float64(len(samples))
+		denominator := count*sumTT - sumT*sumT
+		if denominator == 0 {
+			return 0.0, nil
+		}
+		return (count*sumTV - sumT*sumV) / denominator, nil
+	}
+	return nil, fmt.Errorf("不支持的函数: %s", n.fn)
+}
+
+type callNode struct {
+	name string
+	fn   exprFunc
+	args []exprNode
+}
+
+func (n *callNode) eval(env *exprEnv) (interface{}, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		value, err := evalFloat(arg, env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	return n.fn.call(args)
+}
+
+// compareOperand 将比较运算的操作数转换为数值，布尔值视为1/0以便与上报的bool属性比较
+func compareOperand(value interface{}) (float64, bool) {
+	if b, ok := value.(bool); ok {
+		if b {
+			return 1, true
+		}
+		return 0, true
+	}
+	return toFloat64(value)
+}
+
+// evalBool 计算节点并转换为布尔值，数值非0视为true
+func evalBool(node exprNode, env *exprEnv) (bool, error) {
+	value, err := node.eval(env)
+	if err != nil {
+		return false, err
+	}
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+	if f, ok := toFloat64(value); ok {
+		return f != 0, nil
+	}
+	return false, fmt.Errorf("值不是布尔值: %v", value)
+}
+
+// evalFloat 计算节点并转换为数值
+func evalFloat(node exprNode, env *exprEnv) (float64, error) {
+	value, err := node.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := toFloat64(value)
+	if !ok {
+		return 0, fmt.Errorf("值不是数值: %v", value)
+	}
+	return f, nil
+}
+
+// toFloat64 尝试将模拟值转换为float64
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
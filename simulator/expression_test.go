@@ -0,0 +1,104 @@
+package simulator
+
+import "testing"
+
+func TestCompileExpression(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		wantErr     bool
+		identifiers []string
+	}{
+		{name: "比较和逻辑运算", source: "temperature >= 85 && speed > 2000", identifiers: []string{"speed", "temperature"}},
+		{name: "算术和函数", source: "max(voltage, 220) * current * 0.9", identifiers: []string{"current", "voltage"}},
+		{name: "struct成员", source: "status.code != 0", identifiers: []string{"status.code"}},
+		{name: "带冒号的标识符", source: "sensor:1 > 10", identifiers: []string{"sensor:1"}},
+		{name: "字符串", source: "mode == 'cool'", identifiers: []string{"mode"}},
+		{name: "空表达式", source: "", wantErr: true},
+		{name: "括号不匹配", source: "(a + 1", wantErr: true},
+		{name: "多余内容", source: "a > 1 b", wantErr: true},
+		{name: "缺少操作数", source: "a >", wantErr: true},
+		{name: "未知函数", source: "foo(a)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := CompileExpression(tt.source)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CompileExpression(%q) 应返回错误", tt.source)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompileExpression(%q) 返回错误: %v", tt.source, err)
+			}
+			if len(expr.identifiers) != len(tt.identifiers) {
+				t.Fatalf("identifiers = %v, 期望 %v", expr.identifiers, tt.identifiers)
+			}
+			for i, identifier := range tt.identifiers {
+				if expr.identifiers[i] != identifier {
+					t.Fatalf("identifiers = %v, 期望 %v", expr.identifiers, tt.identifiers)
+				}
+			}
+		})
+	}
+}
+
+func TestExpressionEvaluateBool(t *testing.T) {
+	values := map[string]interface{}{
+		"temperature": "90.5",
+		"speed":       "1500",
+		"mode":        "cool",
+		"zero":        "0",
+		"running":     true,
+		"status":      map[string]interface{}{"code": 3},
+	}
+	resolve := propertyResolver(values)
+
+	tests := []struct {
+		name    string
+		source  string
+		want    bool
+		wantErr bool
+	}{
+		{name: "数值比较", source: "temperature >= 85", want: true},
+		{name: "逻辑与", source: "temperature >= 85 && speed > 2000", want: false},
+		{name: "逻辑或", source: "temperature >= 95 || speed < 2000", want: true},
+		{name: "取反", source: "!(speed > 2000)", want: true},
+		{name: "浮点数相等", source: "0.1 + 0.2 == 0.3", want: true},
+		{name: "浮点数不等", source: "0.1 + 0.2 != 0.3", want: false},
+		{name: "字符串相等", source: "mode == 'cool'", want: true},
+		{name: "字符串不等", source: "mode != \"heat\"", want: true},
+		{name: "布尔属性", source: "running", want: true},
+		{name: "数值非0为true", source: "speed", want: true},
+		{name: "数值0为false", source: "zero", want: false},
+		{name: "struct成员", source: "status.code == 3", want: true},
+		{name: "短路求值", source: "zero > 0 && missing > 1", want: false},
+		{name: "变量不存在", source: "missing > 1", wantErr: true},
+		{name: "除数为0", source: "speed / zero > 1", wantErr: true},
+		{name: "字符串不支持大小比较", source: "mode > 'a'", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := CompileExpression(tt.source)
+			if err != nil {
+				t.Fatalf("CompileExpression(%q) 返回错误: %v", tt.source, err)
+			}
+			got, err := expr.EvaluateBool(resolve)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("EvaluateBool(%q) 应返回错误", tt.source)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EvaluateBool(%q) 返回错误: %v", tt.source, err)
+			}
+			if got != tt.want {
+				t.Fatalf("EvaluateBool(%q) = %v, 期望 %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
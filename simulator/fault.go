@@ -0,0 +1,170 @@
+package simulator
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// 故障状态变化
+const (
+	FaultStarted = "started"
+	FaultEnded   = "ended"
+)
+
+// FaultCallback 故障开始或结束时的回调，transition为FaultStarted或FaultEnded
+type FaultCallback func(identifier string, fault FaultConfig, transition string)
+
+// faultState 属性的故障注入状态
+type faultState struct {
+	active     bool
+	startTime  time.Time
+	endTime    time.Time
+	stuckValue interface{}
+	lastOutput interface{} // 最近一次正常上报的值，stuck故障时冻结该值
+	spikeSign  float64
+}
+
+// validateFaultConfig 验证故障注入配置
+func validateFaultConfig(fault FaultConfig) error {
+	switch fault.Type {
+	case "stuck", "dropout":
+	case "spike":
+		if fault.Magnitude <= 0 {
+			return fmt.Errorf("spike故障需要大于0的magnitude参数")
+		}
+	case "drift":
+		if fault.DriftRate == 0 {
+			return fmt.Errorf("drift故障需要driftRate参数")
+		}
+		if fault.Duration <= 0 {
+			return fmt.Errorf("drift故障需要大于0的duration参数")
+		}
+	default:
+		return fmt.Errorf("不支持的故障类型: %s", fault.Type)
+	}
+
+	if fault.Probability < 0 || fault.Probability > 1 {
+		return fmt.Errorf("故障概率必须在0-1之间")
+	}
+	if fault.Duration < 0 {
+		return fmt.Errorf("故障持续时间不能为负数")
+	}
+	if fault.Probability == 0 && fault.Schedule == nil {
+		return fmt.Errorf("故障需要probability或schedule参数")
+	}
+	if fault.Schedule != nil {
+		if fault.Schedule.Start < 0 || fault.Schedule.Interval < 0 {
+			return fmt.Errorf("故障计划时间不能为负数")
+		}
+		if fault.Duration <= 0 {
+			return fmt.Errorf("按计划注入的故障需要大于0的duration参数")
+		}
+		if fault.Schedule.Interval > 0 && fault.Schedule.Interval <= fault.Duration {
+			return fmt.Errorf("故障计划的interval必须大于duration")
+		}
+	}
+	return nil
+}
+
+// SetFaultCallback 设置故障开始和结束时的回调
+func (ps *PropertySimulator) SetFaultCallback(callback FaultCallback) {
+	ps.faultCallback = callback
+}
+
+// injectFault 注入故障并通知状态变化，不上报时返回nil
+func (ps *PropertySimulator) injectFault(identifier string, fault FaultConfig, value interface{}) interface{} {
+	value, report, transition := ps.ApplyFault(identifier, fault, value)
+	if transition != "" && ps.faultCallback != nil {
+		ps.faultCallback(identifier, fault, transition)
+	}
+	if !report {
+		return nil
+	}
+	return value
+}
+
+// ApplyFault 对生成的属性值注入故障，返回处理后的值、是否上报以及故障状态变化
+func (ps *PropertySimulator) ApplyFault(identifier string, fault FaultConfig, value interface{}) (interface{}, bool, string) {
+	now := time.Now()
+	state, exists := ps.faultStates[identifier]
+	if !exists {
+		state = &faultState{}
+		ps.faultStates[identifier] = state
+	}
+
+	transition := ""
+	if state.active && !now.Before(state.endTime) {
+		state.active = false
+		transition = FaultEnded
+	}
+	if !state.active && ps.shouldStartFault(fault, now) {
+		state.active = true
+		state.startTime = now
+		state.endTime = now.Add(time.Duration(fault.Duration) * time.Second)
+		state.stuckValue = state.lastOutput
+		if state.stuckValue == nil {
+			state.stuckValue = value
+		}
+		state.spikeSign = 1
+		if ps.rng.Intn(2) == 0 {
+			state.spikeSign = -1
+		}
+		transition = FaultStarted
+	}
+
+	if !state.active {
+		state.lastOutput = value
+		return value, true, transition
+	}
+
+	// 持续时间为0的故障只影响当前周期
+	if fault.Duration == 0 {
+		state.active = false
+	}
+
+	switch fault.Type {
+	case "stuck":
+		return state.stuckValue, true, transition
+	case "dropout":
+		return nil, false, transition
+	case "spike":
+		return offsetNumericValue(value, state.spikeSign*fault.Magnitude, fault.Magnitude), true, transition
+	case "drift":
+		hours := now.Sub(state.startTime).Hours()
+		return offsetNumericValue(value, fault.DriftRate*hours, fault.DriftRate), true, transition
+	}
+	return value, true, transition
+}
+
+// shouldStartFault 判断当前是否应开始一次故障
+func (ps *PropertySimulator) shouldStartFault(fault FaultConfig, now time.Time) bool {
+	if fault.Schedule != nil {
+		elapsed := int(now.Sub(ps.startTime).Seconds())
+		start := fault.Schedule.Start
+		if elapsed >= start {
+			offset := elapsed - start
+			if fault.Schedule.Interval > 0 {
+				offset %= fault.Schedule.Interval
+			}
+			if offset < fault.Duration {
+				return true
+			}
+		}
+	}
+	return fault.Probability > 0 && ps.rng.Float64() < fault.Probability
+}
+
+// offsetNumericValue 在数值结果上叠加偏移，非数值结果原样返回
+func offsetNumericValue(value interface{}, delta float64, hint float64) interface{} {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return value
+	}
+	decimalPlaces := maxInt(countDecimalPlaces(str), countDecimalPlaces(strconv.FormatFloat(hint, 'f', -1, 64)))
+	return formatDecimal(val+delta, decimalPlaces)
+}
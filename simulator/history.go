@@ -0,0 +1,71 @@
+package simulator
+
+import (
+	"time"
+)
+
+// historySample 属性的历史样本
+type historySample struct {
+	at    time.Time
+	value float64
+}
+
+// propertyHistory 按趋势函数引用的时间窗口保留属性的历史值
+type propertyHistory struct {
+	windows map[string]time.Duration   // 各属性需要保留的最长时间窗口
+	series  map[string][]historySample // 各属性按时间排序的样本
+}
+
+// newPropertyHistory 创建属性历史
+func newPropertyHistory() *propertyHistory {
+	return &propertyHistory{
+		windows: make(map[string]time.Duration),
+		series:  make(map[string][]historySample),
+	}
+}
+
+// track 登记需要记录历史的属性及时间窗口，同一属性保留最长的窗口
+func (h *propertyHistory) track(windows map[string]time.Duration) {
+	for name, window := range windows {
+		if window > h.windows[name] {
+			h.windows[name] = window
+		}
+	}
+}
+
+// record 记录登记属性的当前数值，并丢弃超出时间窗口的样本
+func (h *propertyHistory) record(resolve exprResolver, now time.Time) {
+	for name, window := range h.windows {
+		raw, exists := resolve(name)
+		if !exists {
+			continue
+		}
+		value, ok := toFloat64(raw)
+		if !ok {
+			continue
+		}
+
+		series := append(h.series[name], historySample{at: now, value: value})
+		cutoff := now.Add(-window)
+		start := 0
+		for start < len(series)-1 && series[start].at.Before(cutoff) {
+			start++
+		}
+		h.series[name] = series[start:]
+	}
+}
+
+// samples 获取属性在最近一个样本之前window时间内的样本
+func (h *propertyHistory) samples(name string, window time.Duration) []historySample {
+	series := h.series[name]
+	if len(series) == 0 {
+		return nil
+	}
+
+	cutoff := series[len(series)-1].at.Add(-window)
+	start := 0
+	for start < len(series)-1 && series[start].at.Before(cutoff) {
+		start++
+	}
+	return series[start:]
+}
@@ -0,0 +1,228 @@
+package simulator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iot-go-sdk/pkg/framework/core"
+	"github.com/iot-go-sdk/pkg/mqtt"
+)
+
+// serviceTopicParts 服务调用主题 $SYS/{ProductKey}/{DeviceName}/service/{ServiceName}/invoke 的段数
+const serviceTopicParts = 6
+
+// mqttClientProvider SDK的MQTT插件，提供底层的MQTT连接
+type mqttClientProvider interface {
+	GetClient() *mqtt.Client
+}
+
+// mqttLink 设备直接收发物模型报文的MQTT连接。
+// SDK的MQTT插件上报属性时会把值转换为字符串、属性设置和服务调用总是回复，
+// 模拟器通过mqttLink按TSL类型上报、回复设置结果，并在模拟不回复时丢弃服务调用
+type mqttLink struct {
+	client     *mqtt.Client
+	productKey string
+	deviceName string
+}
+
+// topic 生成设备的物模型主题，suffix如 property/post
+func (l *mqttLink) topic(suffix string) string {
+	return fmt.Sprintf("$SYS/%s/%s/%s", l.productKey, l.deviceName, suffix)
+}
+
+// publish 序列化并发布报文
+func (l *mqttLink) publish(topic string, msg map[string]interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("序列化报文失败: %v", err)
+	}
+	return l.client.Publish(topic, data, 0, false)
+}
+
+// postProperties 上报属性，值保持转换后的类型
+func (l *mqttLink) postProperties(properties map[string]interface{}) error {
+	timestamp := time.Now().Unix()
+	params := make(map[string]interface{}, len(properties))
+	for identifier, value := range properties {
+		params[identifier] = map[string]interface{}{
+			"value": value,
+			"time":  timestamp,
+		}
+	}
+	return l.publish(l.topic("property/post"), map[string]interface{}{
+		"id":      fmt.Sprintf("%d", time.Now().UnixNano()),
+		"version": "1.0",
+		"params":  params,
+	})
+}
+
+// reply 回复平台的请求，code为200表示成功
+func (l *mqttLink) reply(topic, id string, code int, message string, data interface{}) error {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	msg := map[string]interface{}{
+		"id":   id,
+		"code": code,
+		"data": data,
+	}
+	if message != "" {
+		msg["message"] = message
+	}
+	return l.publish(topic, msg)
+}
+
+// attachMQTT 获取SDK的MQTT连接供设备直接收发报文，未加载MQTT插件时沿用SDK的处理
+func (sd *SimulatedDevice) attachMQTT() {
+	plugin, err := sd.framework.GetPlugin("mqtt")
+	if err != nil {
+		sd.log(fmt.Sprintf("[%s] 未找到MQTT插件，属性值将由SDK转换为字符串上报: %v", sd.DeviceInfo.DeviceName, err))
+		return
+	}
+	provider, ok := plugin.(mqttClientProvider)
+	if !ok || provider.GetClient() == nil {
+		sd.log(fmt.Sprintf("[%s] MQTT插件不提供连接，属性值将由SDK转换为字符串上报", sd.DeviceInfo.DeviceName))
+		return
+	}
+
+	link := &mqttLink{
+		client:     provider.GetClient(),
+		productKey: sd.DeviceInfo.ProductKey,
+		deviceName: sd.DeviceInfo.DeviceName,
+	}
+
+	// 同一主题的处理器会替换SDK插件注册的处理器
+	if err := link.client.Subscribe(link.topic("property/set"), 0, sd.onPropertySetMessage); err != nil {
+		sd.log(fmt.Sprintf("[%s] 订阅属性设置主题失败，属性设置将由SDK回复: %v", sd.DeviceInfo.DeviceName, err))
+	}
+	if err := link.client.Subscribe(link.topic("service/+/invoke"), 0, sd.onServiceMessage); err != nil {
+		sd.log(fmt.Sprintf("[%s] 订阅服务调用主题失败，服务调用将由SDK回复: %v", sd.DeviceInfo.DeviceName, err))
+	}
+
+	sd.mutex.Lock()
+	sd.link = link
+	sd.mutex.Unlock()
+}
+
+// onPropertySetMessage 接收平台的属性设置报文，设置延时在独立的goroutine中执行，不阻塞MQTT回调
+func (sd *SimulatedDevice) onPropertySetMessage(topic string, payload []byte) {
+	go sd.handlePropertySetMessage(payload)
+}
+
+// handlePropertySetMessage 逐个设置属性并回复结果，任一属性设置失败时回复第一个错误
+func (sd *SimulatedDevice) handlePropertySetMessage(payload []byte) {
+	var msg struct {
+		ID     string                 `json:"id"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		sd.log(fmt.Sprintf("[%s] 解析属性设置报文失败: %v", sd.DeviceInfo.DeviceName, err))
+		return
+	}
+
+	identifiers := make([]string, 0, len(msg.Params))
+	for identifier := range msg.Params {
+		identifiers = append(identifiers, identifier)
+	}
+	sort.Strings(identifiers)
+
+	code, message := 200, ""
+	for _, identifier := range identifiers {
+		value := msg.Params[identifier]
+		err := sd.OnPropertySet(core.Property{Name: identifier, Value: value})
+		if err == nil {
+			err = sd.setPropertyValue(identifier, value)
+		}
+		if err != nil && code == 200 {
+			code, message = 400, err.Error()
+			if errors.Is(err, errSetFailure) {
+				code = 500
+			}
+		}
+	}
+
+	link := sd.directLink()
+	if link == nil {
+		sd.log(fmt.Sprintf("[%s] 连接已断开，无法回复属性设置[%s]", sd.DeviceInfo.DeviceName, msg.ID))
+		return
+	}
+	if err := link.reply(link.topic("property/set/reply"), msg.ID, code, message, nil); err != nil {
+		sd.log(fmt.Sprintf("[%s] 回复属性设置失败: %v", sd.DeviceInfo.DeviceName, err))
+	}
+}
+
+// directLink 获取设备直接使用的MQTT连接，未连接时返回nil
+func (sd *SimulatedDevice) directLink() *mqttLink {
+	sd.mutex.RLock()
+	defer sd.mutex.RUnlock()
+	if sd.link == nil || !sd.link.client.IsConnected() {
+		return nil
+	}
+	return sd.link
+}
+
+// onServiceMessage 接收平台的服务调用报文，服务延时在独立的goroutine中执行，不阻塞MQTT回调
+func (sd *SimulatedDevice) onServiceMessage(topic string, payload []byte) {
+	go sd.handleServiceMessage(topic, payload)
+}
+
+// handleServiceMessage 处理服务调用并回复到 service/{ServiceName}/invoke/reply，模拟不回复时丢弃请求。
+// TSL定义的服务按规则模拟，其他服务（如update_tsl）交给OnServiceInvoke处理
+func (sd *SimulatedDevice) handleServiceMessage(topic string, payload []byte) {
+	parts := strings.Split(topic, "/")
+	if len(parts) != serviceTopicParts {
+		sd.log(fmt.Sprintf("[%s] 无效的服务调用主题: %s", sd.DeviceInfo.DeviceName, topic))
+		return
+	}
+	service := parts[4]
+
+	var msg struct {
+		ID     string                 `json:"id"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		sd.log(fmt.Sprintf("[%s] 解析服务调用报文失败: %v", sd.DeviceInfo.DeviceName, err))
+		return
+	}
+
+	var code int
+	var message string
+	var data interface{}
+	if sd.findAction(service) != nil {
+		result, err := sd.handleService(service, msg.Params)
+		if errors.Is(err, errNoReply) {
+			return
+		}
+		// 与SDK对注册服务的回复一致：成功为0，失败为-1
+		if err != nil {
+			code, message = -1, err.Error()
+		} else {
+			data = result
+		}
+	} else {
+		response, err := sd.OnServiceInvoke(core.ServiceRequest{
+			ID:        msg.ID,
+			Service:   service,
+			Params:    msg.Params,
+			Timestamp: time.Now(),
+		})
+		if err != nil {
+			code, message = -1, err.Error()
+		} else {
+			code, message, data = response.Code, response.Message, response.Data
+		}
+	}
+
+	link := sd.directLink()
+	if link == nil {
+		sd.log(fmt.Sprintf("[%s] 连接已断开，无法回复服务[%s]调用", sd.DeviceInfo.DeviceName, service))
+		return
+	}
+	if err := link.reply(topic+"/reply", msg.ID, code, message, data); err != nil {
+		sd.log(fmt.Sprintf("[%s] 回复服务[%s]调用失败: %v", sd.DeviceInfo.DeviceName, service, err))
+	}
+}
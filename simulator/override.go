@@ -0,0 +1,118 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// propertyOverride 平台设置的属性值，生效期间替代模拟生成的值
+type propertyOverride struct {
+	value         interface{}   // 设定值
+	target        float64       // 数值形式的设定值
+	current       float64       // ramp模式下的当前值
+	ramping       bool          // 是否仍在逼近设定值
+	rampRate      float64       // 每秒变化量
+	decimalPlaces int           // ramp过程中值的小数位数
+	hold          time.Duration // 到达设定值后的保持时长，0表示一直保持
+	reachedAt     time.Time     // 到达设定值的时间
+	updatedAt     time.Time     // ramp上次更新时间
+}
+
+// validateSetConfig 验证属性设置配置
+func validateSetConfig(set SetConfig) error {
+	switch set.Mode {
+	case "", "hold":
+	case "ramp":
+		if set.RampRate <= 0 {
+			return fmt.Errorf("ramp模式需要大于0的rampRate参数")
+		}
+	default:
+		return fmt.Errorf("不支持的设置模式: %s", set.Mode)
+	}
+	if set.Hold < 0 {
+		return fmt.Errorf("hold不能为负数")
+	}
+	if set.FailureRate < 0 || set.FailureRate > 1 {
+		return fmt.Errorf("failureRate必须在0-1之间")
+	}
+	if set.Latency < 0 {
+		return fmt.Errorf("latency不能为负数")
+	}
+	return nil
+}
+
+// SetOverride 设置属性值，生效期间替代模拟生成的值，并立即对依赖该属性的表达式等可见
+func (ps *PropertySimulator) SetOverride(identifier string, value interface{}, set SetConfig) {
+	now := time.Now()
+	override := &propertyOverride{
+		value:     value,
+		hold:      time.Duration(set.Hold) * time.Second,
+		reachedAt: now,
+		updatedAt: now,
+	}
+
+	target, numeric := toFloat64(value)
+	override.target = target
+	if numeric && set.Mode == "ramp" {
+		if last, exists := ps.lastValues[identifier]; exists {
+			if current, ok := toFloat64(last); ok && current != target {
+				override.current = current
+				override.ramping = true
+				override.rampRate = set.RampRate
+				override.decimalPlaces = maxInt(countDecimalPlaces(fmt.Sprintf("%v", value)), countDecimalPlaces(strconv.FormatFloat(set.RampRate, 'f', -1, 64)))
+			}
+		}
+	}
+
+	ps.overrides[identifier] = override
+	if !override.ramping {
+		ps.lastValues[identifier] = value
+	}
+}
+
+// ClearOverride 清除属性的设定值，立即恢复模拟
+func (ps *PropertySimulator) ClearOverride(identifier string) {
+	delete(ps.overrides, identifier)
+}
+
+// overrideValue 获取生效中的设定值，保持时间结束后从设定值处恢复模拟
+func (ps *PropertySimulator) overrideValue(identifier string, config PropertySimConfig) (interface{}, bool) {
+	override, exists := ps.overrides[identifier]
+	if !exists {
+		return nil, false
+	}
+
+	now := time.Now()
+	if override.ramping {
+		step := override.rampRate * now.Sub(override.updatedAt).Seconds()
+		diff := override.target - override.current
+		override.updatedAt = now
+		if math.Abs(diff) > step {
+			override.current += math.Copysign(step, diff)
+			return formatDecimal(override.current, override.decimalPlaces), true
+		}
+		override.ramping = false
+		override.reachedAt = now
+	}
+
+	if override.hold > 0 && now.Sub(override.reachedAt) >= override.hold {
+		delete(ps.overrides, identifier)
+		ps.resumeFromOverride(identifier, config, override)
+		return nil, false
+	}
+	return override.value, true
+}
+
+// resumeFromOverride 对以当前值为状态的模拟方法，从设定值处继续模拟
+func (ps *PropertySimulator) resumeFromOverride(identifier string, config PropertySimConfig, override *propertyOverride) {
+	if _, numeric := toFloat64(override.value); !numeric {
+		return
+	}
+	switch config.Method {
+	case "randomWalk", "firstOrderLag", "accumulate", "increase":
+		ps.internalStates[identifier] = override.target
+		ps.updateTimes[identifier] = time.Now()
+	}
+}
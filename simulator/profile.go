@@ -0,0 +1,227 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// profileWeekdays 支持的星期配置键
+var profileWeekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+}
+
+// profilePoint 解析后的控制点，offset为距当天零点的秒数
+type profilePoint struct {
+	offset int
+	value  float64
+}
+
+// parseProfileTime 解析 "HH:MM" 或 "HH:MM:SS" 格式的时间，返回距零点的秒数
+func parseProfileTime(s string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("时间格式无效: %s", s)
+	}
+
+	limits := []int{24, 60, 60}
+	seconds := 0
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || n >= limits[i] {
+			return 0, fmt.Errorf("时间格式无效: %s", s)
+		}
+		seconds = seconds*60 + n
+	}
+	if len(parts) == 2 {
+		seconds *= 60
+	}
+	return seconds, nil
+}
+
+// parseProfilePoints 解析并校验控制点列表，要求时间严格递增
+func parseProfilePoints(points []ProfilePoint) ([]profilePoint, error) {
+	if len(points) == 0 {
+		return nil, fmt.Errorf("至少需要一个控制点")
+	}
+
+	parsed := make([]profilePoint, 0, len(points))
+	for i, point := range points {
+		offset, err := parseProfileTime(point.Time)
+		if err != nil {
+			return nil, err
+		}
+		value, err := point.Value.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("控制点[%s]的value无效: %v", point.Time, err)
+		}
+		if i > 0 && offset <= parsed[i-1].offset {
+			return nil, fmt.Errorf("控制点时间必须严格递增: %s", point.Time)
+		}
+		parsed = append(parsed, profilePoint{offset: offset, value: value})
+	}
+	return parsed, nil
+}
+
+// compiledProfile 解析后的负载曲线，控制点和时区只解析一次
+type compiledProfile struct {
+	location      *time.Location                  // 曲线所在时区，为nil时使用本地时间
+	points        []profilePoint                  // 默认控制点
+	weekdays      map[time.Weekday][]profilePoint // 按星期单独配置的控制点
+	step          bool                            // 是否阶梯插值
+	decimalPlaces int                             // 结果的小数位数
+}
+
+// profileKey 负载曲线配置的缓存键
+func profileKey(profile ProfileConfig) string {
+	data, _ := json.Marshal(profile)
+	return string(data)
+}
+
+// compileProfile 解析负载曲线的控制点和时区
+func compileProfile(profile ProfileConfig) (*compiledProfile, error) {
+	compiled := &compiledProfile{
+		weekdays:      make(map[time.Weekday][]profilePoint),
+		step:          profile.Interpolation == "step",
+		decimalPlaces: profileDecimalPlaces(profile),
+	}
+
+	if profile.Timezone != "" {
+		loc, err := time.LoadLocation(profile.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("时区无效: %v", err)
+		}
+		compiled.location = loc
+	}
+
+	if len(profile.Points) > 0 {
+		points, err := parseProfilePoints(profile.Points)
+		if err != nil {
+			return nil, err
+		}
+		compiled.points = points
+	}
+
+	for day, points := range profile.Weekdays {
+		weekday, ok := profileWeekdays[strings.ToLower(day)]
+		if !ok {
+			return nil, fmt.Errorf("无效的星期: %s", day)
+		}
+		parsed, err := parseProfilePoints(points)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", day, err)
+		}
+		compiled.weekdays[weekday] = parsed
+	}
+
+	return compiled, nil
+}
+
+// validateProfileConfig 验证负载曲线配置
+func validateProfileConfig(profile ProfileConfig) error {
+	switch profile.Interpolation {
+	case "", "linear", "step":
+	default:
+		return fmt.Errorf("不支持的插值方式: %s", profile.Interpolation)
+	}
+
+	if len(profile.Points) == 0 && len(profile.Weekdays) == 0 {
+		return fmt.Errorf("profile方法需要points或weekdays参数")
+	}
+
+	compiled, err := compileProfile(profile)
+	if err != nil {
+		return err
+	}
+
+	// 未配置默认points时，weekdays必须覆盖一周的每一天
+	if len(compiled.points) == 0 && len(compiled.weekdays) < 7 {
+		return fmt.Errorf("未配置points时weekdays必须覆盖一周七天")
+	}
+
+	return nil
+}
+
+// pointsFor 获取指定星期的控制点，未单独配置时使用默认points
+func (p *compiledProfile) pointsFor(weekday time.Weekday) []profilePoint {
+	if points, exists := p.weekdays[weekday]; exists {
+		return points
+	}
+	return p.points
+}
+
+// evaluate 计算给定时刻的曲线值，跨零点时与前一天/后一天的控制点衔接
+func (p *compiledProfile) evaluate(now time.Time) float64 {
+	if p.location != nil {
+		now = now.In(p.location)
+	}
+
+	today := p.pointsFor(now.Weekday())
+	if len(today) == 0 {
+		return 0
+	}
+	offset := now.Hour()*3600 + now.Minute()*60 + now.Second()
+
+	// 找到当前时刻所在区间 [prev, next]
+	idx := sort.Search(len(today), func(i int) bool { return today[i].offset > offset })
+	var prev, next profilePoint
+	if idx == 0 {
+		yesterday := p.pointsFor(now.AddDate(0, 0, -1).Weekday())
+		prev = yesterday[len(yesterday)-1]
+		prev.offset -= 24 * 3600
+		next = today[0]
+	} else if idx == len(today) {
+		prev = today[len(today)-1]
+		tomorrow := p.pointsFor(now.AddDate(0, 0, 1).Weekday())
+		next = tomorrow[0]
+		next.offset += 24 * 3600
+	} else {
+		prev = today[idx-1]
+		next = today[idx]
+	}
+
+	if p.step || next.offset == prev.offset {
+		return prev.value
+	}
+
+	ratio := float64(offset-prev.offset) / float64(next.offset-prev.offset)
+	return prev.value + (next.value-prev.value)*ratio
+}
+
+// profileDecimalPlaces 计算曲线控制点数值的最大小数位数
+func profileDecimalPlaces(profile ProfileConfig) int {
+	places := 0
+	for _, point := range profile.Points {
+		places = maxInt(places, countDecimalPlaces(point.Value.String()))
+	}
+	for _, points := range profile.Weekdays {
+		for _, point := range points {
+			places = maxInt(places, countDecimalPlaces(point.Value.String()))
+		}
+	}
+	return places
+}
+
+// maxInt 返回两个整数中的较大值
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -1,9 +1,12 @@
 package simulator
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
 	"math"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -11,29 +14,89 @@ import (
 
 // PropertySimulator 属性模拟器
 type PropertySimulator struct {
-	internalStates map[string]float64 // 保存累加、上次值等状态
+	internalStates map[string]float64           // 保存累加、上次值等状态
+	updateTimes    map[string]time.Time         // 保存状态的上次更新时间
+	replayTraces   map[string]*replayTrace      // 回放方法使用的已加载数据
+	lastValues     map[string]interface{}       // 各属性最近一次生成的值，供表达式等引用
+	expressions    map[string]*Expression       // 已编译的表达式缓存
+	profiles       map[string]*compiledProfile  // 已解析的负载曲线，键为曲线配置的JSON
+	faultStates    map[string]*faultState       // 故障注入状态
+	faultCallback  FaultCallback                // 故障开始和结束时的回调
+	overrides      map[string]*propertyOverride // 平台设置的属性值
+	startTime      time.Time                    // 模拟开始时间，故障计划以此为基准
+	rng            *rand.Rand                   // 设备级随机数源
 }
 
 // NewPropertySimulator 创建属性模拟器
-func NewPropertySimulator() *PropertySimulator {
+func NewPropertySimulator(rng *rand.Rand) *PropertySimulator {
 	return &PropertySimulator{
+		rng:            rng,
 		internalStates: make(map[string]float64),
+		updateTimes:    make(map[string]time.Time),
+		replayTraces:   make(map[string]*replayTrace),
+		lastValues:     make(map[string]interface{}),
+		expressions:    make(map[string]*Expression),
+		profiles:       make(map[string]*compiledProfile),
+		faultStates:    make(map[string]*faultState),
+		overrides:      make(map[string]*propertyOverride),
+		startTime:      time.Now(),
 	}
 }
 
-// SimulateValue 根据配置和方法生成属性值
+// SimulateValue 根据配置和方法生成属性值，并叠加配置的测量噪声和故障；属性被平台设置时返回设定值。
+// 故障后的值同时作为最近一次的值，表达式、事件和平台读取与上报的值一致，dropout时返回nil
 func (ps *PropertySimulator) SimulateValue(identifier string, config PropertySimConfig) interface{} {
+	if value, active := ps.overrideValue(identifier, config); active {
+		ps.lastValues[identifier] = value
+		return value
+	}
+
+	value := ps.simulateBaseValue(identifier, config)
+	if value == nil {
+		return nil
+	}
+	if config.Noise != nil {
+		value = ps.applyNoise(value, *config.Noise)
+	}
+	if config.Fault != nil {
+		value = ps.injectFault(identifier, *config.Fault, value)
+		if value == nil {
+			return nil
+		}
+	}
+	ps.lastValues[identifier] = value
+	return value
+}
+
+// simulateBaseValue 根据模拟方法生成基础值
+func (ps *PropertySimulator) simulateBaseValue(identifier string, config PropertySimConfig) interface{} {
 	switch config.Method {
 	case "randomRange":
 		return ps.simulateRandomRange(identifier, config)
+	case "randomWalk":
+		return ps.simulateRandomWalk(identifier, config)
 	case "wave":
 		return ps.simulateWave(identifier, config)
+	case "profile":
+		return ps.simulateProfile(identifier, config)
+	case "replay":
+		return ps.simulateReplay(identifier, config)
+	case "expression":
+		return ps.simulateExpression(identifier, config)
+	case "firstOrderLag":
+		return ps.simulateFirstOrderLag(identifier, config)
 	case "accumulate", "increase":
 		return ps.simulateAccumulate(identifier, config)
 	case "enum", "enumPick":
 		return ps.simulateEnum(identifier, config)
 	case "fixed":
 		return ps.simulateFixed(identifier, config)
+	case "timestamp":
+		return strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	case "struct":
+		return ps.simulateStruct(identifier, config)
+	case "array":
+		return ps.simulateArray(identifier, config)
 	default:
 		return "0"
 	}
@@ -53,7 +116,7 @@ func (ps *PropertySimulator) simulateRandomRange(identifier string, config Prope
 	}
 	
 	// 生成随机数并格式化到指定小数位
-	randomValue := minF + rand.Float64()*(maxF-minF)
+	randomValue := minF + ps.rng.Float64()*(maxF-minF)
 	if decimalPlaces == 0 {
 		// 整数类型，返回字符串形式
 		return fmt.Sprintf("%d", int64(math.Round(randomValue)))
@@ -63,6 +126,30 @@ func (ps *PropertySimulator) simulateRandomRange(identifier string, config Prope
 	return fmt.Sprintf("%.*f", decimalPlaces, math.Round(randomValue*scale)/scale)
 }
 
+// simulateRandomWalk 模拟随机游走值，每周期在上次值基础上变化不超过step，并限制在[min, max]内
+func (ps *PropertySimulator) simulateRandomWalk(identifier string, config PropertySimConfig) interface{} {
+	minF, _ := config.Min.Float64()
+	maxF, _ := config.Max.Float64()
+	stepF, _ := config.Step.Float64()
+
+	prevVal, exists := ps.internalStates[identifier]
+	if !exists {
+		// 第一次取start，未配置start时在范围内随机取初值
+		if config.Start != "" {
+			prevVal, _ = config.Start.Float64()
+		} else {
+			prevVal = minF + ps.rng.Float64()*(maxF-minF)
+		}
+	}
+
+	newVal := prevVal + (ps.rng.Float64()*2-1)*stepF
+	newVal = math.Max(minF, math.Min(maxF, newVal))
+	ps.internalStates[identifier] = newVal
+
+	decimalPlaces := maxDecimalPlaces(config.Min, config.Max, config.Step)
+	return formatDecimal(newVal, decimalPlaces)
+}
+
 // simulateWave 模拟波形值
 func (ps *PropertySimulator) simulateWave(identifier string, config PropertySimConfig) interface{} {
 	minF, _ := config.Min.Float64()
@@ -95,6 +182,168 @@ func (ps *PropertySimulator) simulateWave(identifier string, config PropertySimC
 	return fmt.Sprintf("%.*f", decimalPlaces, math.Round(waveVal*scale)/scale)
 }
 
+// simulateProfile 按时段负载曲线插值生成值
+func (ps *PropertySimulator) simulateProfile(identifier string, config PropertySimConfig) interface{} {
+	if config.Profile == nil {
+		return "0"
+	}
+	// 按配置内容缓存，规则更新后重新解析
+	key := profileKey(*config.Profile)
+	profile, exists := ps.profiles[key]
+	if !exists {
+		compiled, err := compileProfile(*config.Profile)
+		if err != nil {
+			log.Printf("属性[%s]的负载曲线无法解析: %v", identifier, err)
+			if last, exists := ps.lastValues[identifier]; exists {
+				return last
+			}
+			return "0"
+		}
+		profile = compiled
+		ps.profiles[key] = profile
+	}
+	return formatDecimal(profile.evaluate(time.Now()), profile.decimalPlaces)
+}
+
+// simulateReplay 回放录制数据，内部状态保存下一个样本序号或回放开始时间
+func (ps *PropertySimulator) simulateReplay(identifier string, config PropertySimConfig) interface{} {
+	trace, exists := ps.replayTraces[identifier]
+	if !exists || config.Replay == nil {
+		return "0"
+	}
+
+	if config.Replay.Align == "timestamp" {
+		now := float64(time.Now().UnixNano()) / 1e9
+		start, exists := ps.internalStates[identifier]
+		if !exists {
+			start = now
+			ps.internalStates[identifier] = start
+		}
+		return trace.valueAtTime(now-start, config.Replay.Loop, config.Replay.Interpolate)
+	}
+
+	index := int(ps.internalStates[identifier])
+	ps.internalStates[identifier] = float64(index + 1)
+	return trace.valueAt(index, config.Replay.Loop)
+}
+
+// LoadReplayTrace 加载属性的回放数据文件
+func (ps *PropertySimulator) LoadReplayTrace(identifier, path string, config ReplayConfig) error {
+	trace, err := loadReplayTrace(path, config, identifier)
+	if err != nil {
+		return err
+	}
+	ps.replayTraces[identifier] = trace
+	return nil
+}
+
+// simulateExpression 根据其他属性最近的值计算派生属性，结果精度取step的小数位数（默认2位）。
+// 计算失败时沿用上一次的值，没有上一次的值时返回nil，本周期不上报该属性
+func (ps *PropertySimulator) simulateExpression(identifier string, config PropertySimConfig) interface{} {
+	expr, exists := ps.expressions[config.Expression]
+	if !exists {
+		compiled, err := CompileExpression(config.Expression)
+		if err != nil {
+			log.Printf("属性[%s]的表达式无法解析: %s: %v", identifier, config.Expression, err)
+			return ps.expressionFallback(identifier, config)
+		}
+		expr = compiled
+		ps.expressions[config.Expression] = expr
+	}
+
+	value, err := expr.EvaluateFloat(ps.lookupValue)
+	if err != nil {
+		log.Printf("属性[%s]的表达式计算失败: %v", identifier, err)
+		return ps.expressionFallback(identifier, config)
+	}
+
+	return formatDecimal(value, expressionDecimalPlaces(config))
+}
+
+// expressionFallback 表达式失败时沿用上一次的值，还没有值时使用min（未配置时为0）
+func (ps *PropertySimulator) expressionFallback(identifier string, config PropertySimConfig) interface{} {
+	if last, exists := ps.lastValues[identifier]; exists {
+		return last
+	}
+	fallback, _ := config.Min.Float64()
+	log.Printf("属性[%s]还没有可沿用的值，使用%v", identifier, fallback)
+	return formatDecimal(fallback, expressionDecimalPlaces(config))
+}
+
+// expressionDecimalPlaces 表达式结果的小数位数，按step确定，默认2位
+func expressionDecimalPlaces(config PropertySimConfig) int {
+	if config.Step != "" {
+		return countDecimalPlaces(config.Step.String())
+	}
+	return 2
+}
+
+// simulateFirstOrderLag 模拟一阶惯性响应，按实际经过时间以timeConstant为时间常数逼近目标值
+func (ps *PropertySimulator) simulateFirstOrderLag(identifier string, config PropertySimConfig) interface{} {
+	now := time.Now()
+	target, hasTarget := ps.resolveTarget(config.Target)
+
+	current, exists := ps.internalStates[identifier]
+	if !exists {
+		switch {
+		case config.Start != "":
+			current, _ = config.Start.Float64()
+		case hasTarget:
+			current = target
+		}
+	} else if hasTarget {
+		elapsed := now.Sub(ps.updateTimes[identifier]).Seconds()
+		if elapsed > 0 {
+			current += (target - current) * (1 - math.Exp(-elapsed/config.TimeConstant))
+			// 扰动强度按每分钟标准差计算
+			if config.Disturbance > 0 {
+				current += ps.rng.NormFloat64() * config.Disturbance * math.Sqrt(elapsed/60)
+			}
+		}
+	}
+
+	if config.Min != "" {
+		minF, _ := config.Min.Float64()
+		current = math.Max(minF, current)
+	}
+	if config.Max != "" {
+		maxF, _ := config.Max.Float64()
+		current = math.Min(maxF, current)
+	}
+
+	ps.internalStates[identifier] = current
+	ps.updateTimes[identifier] = now
+
+	decimalPlaces := 2
+	if config.Step != "" {
+		decimalPlaces = countDecimalPlaces(config.Step.String())
+	}
+	return formatDecimal(current, decimalPlaces)
+}
+
+// resolveTarget 解析目标值，可以是数值常量或其他属性的标识符
+func (ps *PropertySimulator) resolveTarget(target string) (float64, bool) {
+	if f, err := strconv.ParseFloat(target, 64); err == nil {
+		return f, true
+	}
+	value, exists := ps.lastValues[target]
+	if !exists {
+		return 0, false
+	}
+	return toFloat64(value)
+}
+
+// lookupValue 获取属性最近一次生成的值
+func (ps *PropertySimulator) lookupValue(identifier string) (interface{}, bool) {
+	value, exists := ps.lastValues[identifier]
+	return value, exists
+}
+
+// GetLastValue 获取属性最近一次生成的值
+func (ps *PropertySimulator) GetLastValue(identifier string) (interface{}, bool) {
+	return ps.lookupValue(identifier)
+}
+
 // simulateAccumulate 模拟累加值
 func (ps *PropertySimulator) simulateAccumulate(identifier string, config PropertySimConfig) interface{} {
 	prevVal := ps.internalStates[identifier]
@@ -114,18 +363,21 @@ func (ps *PropertySimulator) simulateEnum(identifier string, config PropertySimC
 	if len(config.EnumValues) == 0 {
 		return ""
 	}
+	if len(config.Transitions) > 0 {
+		return ps.simulateEnumMarkov(identifier, config)
+	}
 	
 	var idx int
 	if prev, ok := ps.internalStates[identifier]; ok {
 		idx = int(prev)
 		// 根据切换概率决定是否切换到新值
-		if rand.Float64() < config.SwitchProbability {
-			idx = rand.Intn(len(config.EnumValues))
+		if ps.rng.Float64() < config.SwitchProbability {
+			idx = ps.rng.Intn(len(config.EnumValues))
 			ps.internalStates[identifier] = float64(idx)
 		}
 	} else {
 		// 第一次选择随机值
-		idx = rand.Intn(len(config.EnumValues))
+		idx = ps.rng.Intn(len(config.EnumValues))
 		ps.internalStates[identifier] = float64(idx)
 	}
 	
@@ -136,19 +388,156 @@ func (ps *PropertySimulator) simulateEnum(identifier string, config PropertySimC
 	return ""
 }
 
+// simulateEnumMarkov 按转移概率矩阵和停留时间模拟枚举状态机，初始状态为enumValues的第一个值
+func (ps *PropertySimulator) simulateEnumMarkov(identifier string, config PropertySimConfig) interface{} {
+	now := time.Now()
+	prev, exists := ps.internalStates[identifier]
+	if !exists {
+		ps.internalStates[identifier] = 0
+		ps.updateTimes[identifier] = now
+		return config.EnumValues[0]
+	}
+
+	idx := int(prev)
+	if idx < 0 || idx >= len(config.EnumValues) {
+		idx = 0
+	}
+	current := config.EnumValues[idx]
+	dwell := config.DwellTimes[current]
+	elapsed := now.Sub(ps.updateTimes[identifier])
+
+	// 未达到最短停留时间时保持当前状态
+	if elapsed < time.Duration(dwell.Min)*time.Second {
+		return current
+	}
+
+	// 超过最长停留时间时强制离开当前状态
+	forceLeave := dwell.Max > 0 && elapsed >= time.Duration(dwell.Max)*time.Second
+	next := pickTransition(ps.rng, config.Transitions[current], current, forceLeave)
+	if next == "" || next == current {
+		return current
+	}
+
+	for i, value := range config.EnumValues {
+		if value == next {
+			ps.internalStates[identifier] = float64(i)
+			ps.updateTimes[identifier] = now
+			break
+		}
+	}
+	return next
+}
+
+// pickTransition 按概率选择下一个状态，excludeSelf时排除停留在当前状态
+func pickTransition(rng *rand.Rand, row map[string]float64, current string, excludeSelf bool) string {
+	// 按状态名排序保证同一随机数得到相同结果
+	targets := make([]string, 0, len(row))
+	total := 0.0
+	for to, probability := range row {
+		if probability <= 0 || (excludeSelf && to == current) {
+			continue
+		}
+		targets = append(targets, to)
+		total += probability
+	}
+	if total <= 0 {
+		return ""
+	}
+	sort.Strings(targets)
+
+	r := rng.Float64() * total
+	for _, to := range targets {
+		r -= row[to]
+		if r < 0 {
+			return to
+		}
+	}
+	return targets[len(targets)-1]
+}
+
 // simulateFixed 模拟固定值
 func (ps *PropertySimulator) simulateFixed(identifier string, config PropertySimConfig) interface{} {
 	return config.Value.String()
 }
 
+// simulateStruct 按成员配置逐个生成struct的成员值，成员状态以"属性.成员"为标识
+func (ps *PropertySimulator) simulateStruct(identifier string, config PropertySimConfig) interface{} {
+	names := make([]string, 0, len(config.Members))
+	for name := range config.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	value := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		// 没有值的成员（如dropout故障）不上报
+		if member := ps.SimulateValue(identifier+"."+name, config.Members[name]); member != nil {
+			value[name] = member
+		}
+	}
+	return value
+}
+
+// simulateArray 按元素配置生成array的各个元素，元素状态以"属性.序号"为标识
+func (ps *PropertySimulator) simulateArray(identifier string, config PropertySimConfig) interface{} {
+	value := make([]interface{}, config.Size)
+	for i := range value {
+		value[i] = ps.SimulateValue(fmt.Sprintf("%s.%d", identifier, i), *config.Item)
+	}
+	return value
+}
+
+// applyNoise 在数值结果上叠加噪声，非数值结果原样返回
+func (ps *PropertySimulator) applyNoise(value interface{}, noise NoiseConfig) interface{} {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return value
+	}
+
+	if noise.Gaussian > 0 {
+		val += ps.rng.NormFloat64() * noise.Gaussian
+	}
+	if noise.Uniform > 0 {
+		val += (ps.rng.Float64()*2 - 1) * noise.Uniform
+	}
+	if noise.OutlierProbability > 0 && ps.rng.Float64() < noise.OutlierProbability {
+		if ps.rng.Intn(2) == 0 {
+			val += noise.OutlierMagnitude
+		} else {
+			val -= noise.OutlierMagnitude
+		}
+	}
+
+	// 小数位数取原值与噪声参数中的最大值，避免噪声被取整抹掉
+	decimalPlaces := countDecimalPlaces(str)
+	for _, param := range []float64{noise.Gaussian, noise.Uniform, noise.Resolution} {
+		if d := countDecimalPlaces(strconv.FormatFloat(param, 'f', -1, 64)); d > decimalPlaces {
+			decimalPlaces = d
+		}
+	}
+	if noise.Resolution > 0 {
+		// 模拟ADC量化
+		val = math.Round(val/noise.Resolution) * noise.Resolution
+		decimalPlaces = countDecimalPlaces(strconv.FormatFloat(noise.Resolution, 'f', -1, 64))
+	}
+
+	return formatDecimal(val, decimalPlaces)
+}
+
 // ResetState 重置指定属性的内部状态
 func (ps *PropertySimulator) ResetState(identifier string) {
 	delete(ps.internalStates, identifier)
+	delete(ps.updateTimes, identifier)
 }
 
 // ResetAllStates 重置所有属性的内部状态
 func (ps *PropertySimulator) ResetAllStates() {
 	ps.internalStates = make(map[string]float64)
+	ps.updateTimes = make(map[string]time.Time)
 }
 
 // GetState 获取属性的内部状态
@@ -171,6 +560,27 @@ func (ps *PropertySimulator) GetAllStates() map[string]float64 {
 	return states
 }
 
+// GetAllUpdateTimes 获取所有状态的上次更新时间
+func (ps *PropertySimulator) GetAllUpdateTimes() map[string]time.Time {
+	updateTimes := make(map[string]time.Time)
+	for k, v := range ps.updateTimes {
+		updateTimes[k] = v
+	}
+	return updateTimes
+}
+
+// SetUpdateTime 设置状态的上次更新时间（用于状态恢复）
+func (ps *PropertySimulator) SetUpdateTime(identifier string, t time.Time) {
+	ps.updateTimes[identifier] = t
+}
+
+// sameBoolValue 判断两个值是否表示相同的布尔值，平台下发的bool为0/1而规则中可能为true/false
+func sameBoolValue(a, b string) bool {
+	boolA, errA := strconv.ParseBool(a)
+	boolB, errB := strconv.ParseBool(b)
+	return errA == nil && errB == nil && boolA == boolB
+}
+
 // countDecimalPlaces 计算小数位数
 func countDecimalPlaces(s string) int {
 	parts := strings.Split(s, ".")
@@ -180,10 +590,30 @@ func countDecimalPlaces(s string) int {
 	return len(parts[1])
 }
 
+// maxDecimalPlaces 计算多个配置数值中最大的小数位数
+func maxDecimalPlaces(numbers ...json.Number) int {
+	places := 0
+	for _, n := range numbers {
+		if d := countDecimalPlaces(n.String()); d > places {
+			places = d
+		}
+	}
+	return places
+}
+
+// formatDecimal 按小数位数格式化数值，整数返回不带小数点的字符串
+func formatDecimal(value float64, decimalPlaces int) string {
+	if decimalPlaces == 0 {
+		return fmt.Sprintf("%d", int64(math.Round(value)))
+	}
+	scale := math.Pow10(decimalPlaces)
+	return fmt.Sprintf("%.*f", decimalPlaces, math.Round(value*scale)/scale)
+}
+
 // ValidatePropertyValue 验证属性值是否符合配置
 func (ps *PropertySimulator) ValidatePropertyValue(value interface{}, config PropertySimConfig) error {
 	switch config.Method {
-	case "randomRange", "wave":
+	case "randomRange", "randomWalk", "wave":
 		// 验证数值范围
 		var val float64
 		switch v := value.(type) {
@@ -210,7 +640,7 @@ func (ps *PropertySimulator) ValidatePropertyValue(value interface{}, config Pro
 		strVal := fmt.Sprintf("%v", value)
 		valid := false
 		for _, enumVal := range config.EnumValues {
-			if strVal == enumVal {
+			if strVal == enumVal || sameBoolValue(strVal, enumVal) {
 				valid = true
 				break
 			}
@@ -0,0 +1,45 @@
+package simulator
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// lockedSource 并发安全的随机数源，设备内的各模拟器共享同一个源
+type lockedSource struct {
+	mutex sync.Mutex
+	src   rand.Source64
+}
+
+// newSeededRand 创建使用指定种子的并发安全随机数生成器
+func newSeededRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.src.Seed(seed)
+}
+
+// DeriveDeviceSeed 由规则中的种子和设备三元组派生设备种子，保证同一规则下不同设备的随机序列互不相同且可复现
+func DeriveDeviceSeed(baseSeed int64, productKey, deviceName string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(productKey))
+	h.Write([]byte{0})
+	h.Write([]byte(deviceName))
+	return baseSeed ^ int64(h.Sum64())
+}
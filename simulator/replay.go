@@ -0,0 +1,246 @@
+package simulator
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// replayTrace 已加载的回放时间序列
+type replayTrace struct {
+	times  []float64 // 样本时间戳(秒)，仅在配置了timeColumn时有效
+	values []string  // 样本值
+}
+
+// validateReplayConfig 验证回放配置
+func validateReplayConfig(replay ReplayConfig) error {
+	if replay.File == "" {
+		return fmt.Errorf("replay方法需要file参数")
+	}
+	switch replay.Format {
+	case "", "csv", "jsonl":
+	default:
+		return fmt.Errorf("不支持的回放文件格式: %s", replay.Format)
+	}
+	switch replay.Align {
+	case "", "index":
+		if replay.Interpolate {
+			return fmt.Errorf("interpolate仅支持按timestamp对齐")
+		}
+	case "timestamp":
+		if replay.TimeColumn == "" {
+			return fmt.Errorf("按timestamp对齐需要timeColumn参数")
+		}
+	default:
+		return fmt.Errorf("不支持的对齐方式: %s", replay.Align)
+	}
+	return nil
+}
+
+// loadReplayTrace 从CSV或JSONL文件加载回放数据，valueColumn为空时使用属性标识符
+func loadReplayTrace(path string, replay ReplayConfig, identifier string) (*replayTrace, error) {
+	format := replay.Format
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			format = "csv"
+		case ".jsonl", ".ndjson":
+			format = "jsonl"
+		default:
+			return nil, fmt.Errorf("无法从扩展名识别回放文件格式: %s", path)
+		}
+	}
+
+	valueColumn := replay.Column
+	if valueColumn == "" {
+		valueColumn = identifier
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开回放文件失败: %v", err)
+	}
+	defer file.Close()
+
+	var trace *replayTrace
+	if format == "csv" {
+		trace, err = readCSVTrace(file, valueColumn, replay.TimeColumn)
+	} else {
+		trace, err = readJSONLTrace(file, valueColumn, replay.TimeColumn)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析回放文件[%s]失败: %v", path, err)
+	}
+
+	if len(trace.values) == 0 {
+		return nil, fmt.Errorf("回放文件[%s]没有数据", path)
+	}
+	for i := 1; i < len(trace.times); i++ {
+		if trace.times[i] <= trace.times[i-1] {
+			return nil, fmt.Errorf("回放文件[%s]第%d个样本的时间戳未递增", path, i+1)
+		}
+	}
+
+	return trace, nil
+}
+
+// readCSVTrace 读取带表头的CSV回放数据
+func readCSVTrace(r io.Reader, valueColumn, timeColumn string) (*replayTrace, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取表头失败: %v", err)
+	}
+
+	valueIdx, timeIdx := -1, -1
+	for i, name := range header {
+		switch strings.TrimSpace(name) {
+		case valueColumn:
+			valueIdx = i
+		case timeColumn:
+			timeIdx = i
+		}
+	}
+	if valueIdx < 0 {
+		return nil, fmt.Errorf("缺少数据列: %s", valueColumn)
+	}
+	if timeColumn != "" && timeIdx < 0 {
+		return nil, fmt.Errorf("缺少时间列: %s", timeColumn)
+	}
+
+	trace := &replayTrace{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("第%d行格式错误: %v", line, err)
+		}
+
+		value := strings.TrimSpace(record[valueIdx])
+		if value == "" {
+			return nil, fmt.Errorf("第%d行缺少值", line)
+		}
+		trace.values = append(trace.values, value)
+
+		if timeIdx >= 0 {
+			ts, err := parseReplayTimestamp(strings.TrimSpace(record[timeIdx]))
+			if err != nil {
+				return nil, fmt.Errorf("第%d行时间戳无效: %v", line, err)
+			}
+			trace.times = append(trace.times, ts)
+		}
+	}
+	return trace, nil
+}
+
+// readJSONLTrace 读取每行一个JSON对象的回放数据
+func readJSONLTrace(r io.Reader, valueField, timeField string) (*replayTrace, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	trace := &replayTrace{}
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.UseNumber()
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			return nil, fmt.Errorf("第%d行JSON无效: %v", line, err)
+		}
+
+		raw, exists := record[valueField]
+		if !exists || raw == nil {
+			return nil, fmt.Errorf("第%d行缺少字段: %s", line, valueField)
+		}
+		trace.values = append(trace.values, fmt.Sprintf("%v", raw))
+
+		if timeField != "" {
+			rawTime, exists := record[timeField]
+			if !exists {
+				return nil, fmt.Errorf("第%d行缺少时间字段: %s", line, timeField)
+			}
+			ts, err := parseReplayTimestamp(fmt.Sprintf("%v", rawTime))
+			if err != nil {
+				return nil, fmt.Errorf("第%d行时间戳无效: %v", line, err)
+			}
+			trace.times = append(trace.times, ts)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+// parseReplayTimestamp 解析时间戳，支持秒/毫秒级Unix时间和RFC3339格式
+func parseReplayTimestamp(s string) (float64, error) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		// 超过1e11视为毫秒
+		if n > 1e11 {
+			return n / 1000, nil
+		}
+		return n, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, err
+	}
+	return float64(t.UnixNano()) / 1e9, nil
+}
+
+// valueAt 按样本序号取值，到达末尾时循环或保持最后一个值
+func (t *replayTrace) valueAt(index int, loop bool) string {
+	if loop {
+		return t.values[index%len(t.values)]
+	}
+	if index >= len(t.values) {
+		return t.values[len(t.values)-1]
+	}
+	return t.values[index]
+}
+
+// valueAtTime 按相对于首个样本的经过秒数取值，可选线性插值
+func (t *replayTrace) valueAtTime(elapsed float64, loop, interpolate bool) string {
+	start := t.times[0]
+	span := t.times[len(t.times)-1] - start
+	if loop && span > 0 {
+		elapsed = math.Mod(elapsed, span)
+	}
+	target := start + elapsed
+
+	// 找到最后一个不晚于目标时间的样本
+	idx := 0
+	for idx+1 < len(t.times) && t.times[idx+1] <= target {
+		idx++
+	}
+	if !interpolate || idx+1 >= len(t.times) {
+		return t.values[idx]
+	}
+
+	prev, errPrev := strconv.ParseFloat(t.values[idx], 64)
+	next, errNext := strconv.ParseFloat(t.values[idx+1], 64)
+	if errPrev != nil || errNext != nil {
+		return t.values[idx]
+	}
+
+	ratio := (target - t.times[idx]) / (t.times[idx+1] - t.times[idx])
+	decimalPlaces := maxInt(countDecimalPlaces(t.values[idx]), countDecimalPlaces(t.values[idx+1]))
+	if decimalPlaces == 0 {
+		decimalPlaces = 2
+	}
+	return formatDecimal(prev+(next-prev)*ratio, decimalPlaces)
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -15,40 +18,152 @@ type SimulationRule struct {
 	SimulationConfig map[string]PropertySimConfig `json:"simulationConfig"`
 	Events           []EventSimConfig             `json:"events"`
 	Services         map[string]ServiceSimConfig  `json:"services"`
+	Seed             *int64                       `json:"seed,omitempty"`       // 随机种子，按设备三元组派生各设备的种子
+	BoolFormat       string                       `json:"boolFormat,omitempty"` // bool类型的上报格式：number(0/1，默认)、bool(true/false)
+
+	sourceDir string // 规则文件所在目录，用于解析回放文件等相对路径
 }
 
 // PropertySimConfig 定义属性模拟配置
 type PropertySimConfig struct {
-	Method            string      `json:"method"`
-	Min               json.Number `json:"min,omitempty"`
-	Max               json.Number `json:"max,omitempty"`
-	Step              json.Number `json:"step,omitempty"`
-	Start             json.Number `json:"start,omitempty"`
-	Value             json.Number `json:"value,omitempty"`
-	EnumValues        []string    `json:"enumValues,omitempty"`
-	SwitchProbability float64     `json:"switchProbability,omitempty"`
-	Amplitude         json.Number `json:"amplitude,omitempty"`
-	WavePeriod        int         `json:"wavePeriod,omitempty"`
+	Method            string                        `json:"method"`
+	Min               json.Number                   `json:"min,omitempty"`
+	Max               json.Number                   `json:"max,omitempty"`
+	Step              json.Number                   `json:"step,omitempty"`
+	Start             json.Number                   `json:"start,omitempty"`
+	Value             json.Number                   `json:"value,omitempty"`
+	EnumValues        []string                      `json:"enumValues,omitempty"`
+	SwitchProbability float64                       `json:"switchProbability,omitempty"`
+	Amplitude         json.Number                   `json:"amplitude,omitempty"`
+	WavePeriod        int                           `json:"wavePeriod,omitempty"`
+	Noise             *NoiseConfig                  `json:"noise,omitempty"`
+	Profile           *ProfileConfig                `json:"profile,omitempty"`
+	Replay            *ReplayConfig                 `json:"replay,omitempty"`
+	Expression        string                        `json:"expression,omitempty"`
+	Target            string                        `json:"target,omitempty"`
+	TimeConstant      float64                       `json:"timeConstant,omitempty"`
+	Disturbance       float64                       `json:"disturbance,omitempty"`
+	Transitions       map[string]map[string]float64 `json:"transitions,omitempty"`
+	DwellTimes        map[string]DwellTime          `json:"dwellTimes,omitempty"`
+	Fault             *FaultConfig                  `json:"fault,omitempty"`
+	Members           map[string]PropertySimConfig  `json:"members,omitempty"` // struct方法各成员的模拟配置
+	Item              *PropertySimConfig            `json:"item,omitempty"`    // array方法元素的模拟配置
+	Size              int                           `json:"size,omitempty"`    // array方法的元素个数
+	OnSet             *SetConfig                    `json:"onSet,omitempty"`   // 平台设置属性后的行为
+}
+
+// SetConfig 定义平台设置属性后的行为，未配置时设定值一直保持到下次设置
+type SetConfig struct {
+	Mode        string  `json:"mode,omitempty"`        // hold(直接保持设定值，默认)、ramp(按rampRate逐步逼近设定值)
+	Hold        int     `json:"hold,omitempty"`        // 到达设定值后保持的秒数，之后恢复模拟，0表示一直保持
+	RampRate    float64 `json:"rampRate,omitempty"`    // ramp模式每秒的变化量
+	FailureRate float64 `json:"failureRate,omitempty"` // 设置失败的概率
+	Latency     int     `json:"latency,omitempty"`     // 设置响应延时(毫秒)
+}
+
+// DwellTime 定义枚举状态的最短/最长停留时间（秒），max为0表示不限
+type DwellTime struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+// NoiseConfig 定义测量噪声配置，在基础模拟方法之后叠加
+type NoiseConfig struct {
+	Gaussian           float64 `json:"gaussian,omitempty"`           // 高斯噪声标准差
+	Uniform            float64 `json:"uniform,omitempty"`            // 均匀抖动幅度(±)
+	Resolution         float64 `json:"resolution,omitempty"`         // ADC量化分辨率
+	OutlierProbability float64 `json:"outlierProbability,omitempty"` // 离群值出现概率
+	OutlierMagnitude   float64 `json:"outlierMagnitude,omitempty"`   // 离群值偏移幅度
 }
 
 // EventSimConfig 定义事件模拟配置
 type EventSimConfig struct {
-	Identifier       string `json:"identifier"`
-	TriggerCondition string `json:"triggerCondition"`
-	Cooldown         int    `json:"cooldown"`
+	Identifier       string                    `json:"identifier"`
+	TriggerCondition string                    `json:"triggerCondition"`
+	Cooldown         int                       `json:"cooldown"`
+	Sustain          int                       `json:"sustain,omitempty"`        // 触发条件需持续满足的秒数，与sustainCycles满足其一即可
+	SustainCycles    int                       `json:"sustainCycles,omitempty"`  // 触发条件需连续满足的上报周期数
+	ClearCondition   string                    `json:"clearCondition,omitempty"` // 告警恢复条件，未配置时触发条件不再满足即恢复
+	RecoveryEvent    string                    `json:"recoveryEvent,omitempty"`  // 告警恢复时上报的事件标识符
+	Stochastic       *StochasticConfig         `json:"stochastic,omitempty"`     // 与属性无关的随机触发，配置后不使用triggerCondition
+	Params           map[string]EventParamRule `json:"params,omitempty"`         // TSL输出参数的取值规则，键为输出参数标识符
+}
+
+// EventParamRule 定义事件输出参数的取值规则
+type EventParamRule struct {
+	Method     string             `json:"method"`               // property(当前属性值)、constant(常量)、expression(按属性计算)、generator(按属性模拟方法生成)
+	Property   string             `json:"property,omitempty"`   // property读取的属性标识符
+	Value      interface{}        `json:"value,omitempty"`      // constant的值
+	Expression string             `json:"expression,omitempty"` // expression的表达式
+	Generator  *PropertySimConfig `json:"generator,omitempty"`  // generator的模拟配置
+}
+
+// StochasticConfig 定义按发生率随机触发的事件
+type StochasticConfig struct {
+	Type         string  `json:"type"`                   // poisson(按平均间隔随机发生)、mtbf(故障与修复交替发生)
+	MeanInterval float64 `json:"meanInterval,omitempty"` // poisson的平均间隔(秒)
+	MTBF         float64 `json:"mtbf,omitempty"`         // 平均故障间隔(秒)
+	MTTR         float64 `json:"mttr,omitempty"`         // 平均修复时间(秒)，修复时上报recoveryEvent
 }
 
 // ServiceSimConfig 定义服务模拟配置
 type ServiceSimConfig struct {
-	ResponseStrategy  string            `json:"responseStrategy"`
-	PossibleResponses []ServiceResponse `json:"possibleResponses"`
+	ResponseStrategy      string                       `json:"responseStrategy"`
+	PossibleResponses     []ServiceResponse            `json:"possibleResponses"`
+	Effects               map[string]ServiceEffect     `json:"effects,omitempty"`               // 调用成功后对属性的影响，键为属性标识符
+	Latency               *LatencyConfig               `json:"latency,omitempty"`               // 响应延时分布，未配置时固定3秒
+	NoReplyProbability    float64                      `json:"noReplyProbability,omitempty"`    // 设备不回复的概率，用于测试平台的调用超时
+	OptionalParams        []string                     `json:"optionalParams,omitempty"`        // 可以缺省的输入参数，其余TSL输入参数均为必填
+	InvalidParamsResponse *ServiceResponse             `json:"invalidParamsResponse,omitempty"` // 参数校验失败时的响应，默认400
+	Outputs               map[string]ServiceOutputRule `json:"outputs,omitempty"`               // TSL输出参数的生成规则，键为输出参数标识符
+	Async                 *AsyncServiceConfig          `json:"async,omitempty"`                 // TSL中callType为async的服务的结果和进度上报
+}
+
+// AsyncServiceConfig 定义异步服务的结果和进度上报，结果在latency延时后上报
+type AsyncServiceConfig struct {
+	ResultEvent      string `json:"resultEvent,omitempty"`      // 上报执行结果的事件标识符，默认为"服务标识符_result"
+	ProgressEvent    string `json:"progressEvent,omitempty"`    // 上报处理进度的事件标识符，为空时不上报进度
+	ProgressInterval int    `json:"progressInterval,omitempty"` // 进度上报间隔(秒)
+}
+
+// ServiceOutputRule 定义服务输出参数的生成规则
+type ServiceOutputRule struct {
+	Method   string      `json:"method"`             // fixed(固定值)、range(随机范围)、param(回显输入参数)、property(当前属性值)
+	Value    interface{} `json:"value,omitempty"`    // fixed的值
+	Min      float64     `json:"min,omitempty"`      // range的下限
+	Max      float64     `json:"max,omitempty"`      // range的上限
+	Param    string      `json:"param,omitempty"`    // param回显的输入参数标识符
+	Property string      `json:"property,omitempty"` // property读取的属性标识符
+}
+
+// LatencyConfig 定义服务响应延时的分布，单位毫秒
+type LatencyConfig struct {
+	Distribution string  `json:"distribution,omitempty"` // fixed(默认)、uniform、normal、longTail
+	Value        float64 `json:"value,omitempty"`        // fixed的延时
+	Min          float64 `json:"min,omitempty"`          // uniform的下限，也作为其他分布的下限
+	Max          float64 `json:"max,omitempty"`          // uniform的上限，也作为其他分布的上限(0表示不限)
+	Mean         float64 `json:"mean,omitempty"`         // normal的均值
+	StdDev       float64 `json:"stddev,omitempty"`       // normal的标准差
+	Median       float64 `json:"median,omitempty"`       // longTail(对数正态分布)的中位数
+	P99          float64 `json:"p99,omitempty"`          // longTail的99分位数
+}
+
+// ServiceEffect 定义服务调用成功后对属性的设置
+type ServiceEffect struct {
+	Value    interface{} `json:"value,omitempty"`    // 设定值，枚举可以使用键或显示文本
+	Param    string      `json:"param,omitempty"`    // 使用服务入参作为设定值
+	Mode     string      `json:"mode,omitempty"`     // hold(直接设定，默认)、ramp(按rampRate逐步逼近设定值)
+	RampRate float64     `json:"rampRate,omitempty"` // ramp模式每秒的变化量
+	Hold     int         `json:"hold,omitempty"`     // 到达设定值后保持的秒数，之后恢复模拟，0表示一直保持
 }
 
 // ServiceResponse 定义服务响应
 type ServiceResponse struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg"`
-	Desc string `json:"desc"`
+	Code      int     `json:"code"`
+	Msg       string  `json:"msg"`
+	Desc      string  `json:"desc"`
+	Weight    float64 `json:"weight,omitempty"`    // weighted策略下的权重
+	Condition string  `json:"condition,omitempty"` // conditional策略下的匹配条件，引用服务输入参数，如 "target_speed > 3000"
 }
 
 // RuleManager 规则管理器
@@ -83,6 +198,7 @@ func (m *RuleManager) LoadRule(filename string) (*SimulationRule, error) {
 	if err := json.Unmarshal(data, &rule); err != nil {
 		return nil, fmt.Errorf("解析规则失败: %v", err)
 	}
+	rule.sourceDir = filepath.Dir(filePath)
 
 	return &rule, nil
 }
@@ -136,6 +252,12 @@ func (m *RuleManager) ValidateRule(rule *SimulationRule) error {
 		return fmt.Errorf("产品名称不能为空")
 	}
 
+	switch rule.BoolFormat {
+	case "", "number", "bool":
+	default:
+		return fmt.Errorf("不支持的boolFormat: %s", rule.BoolFormat)
+	}
+
 	// 验证属性配置
 	for identifier, config := range rule.SimulationConfig {
 		if identifier == "" {
@@ -147,6 +269,16 @@ func (m *RuleManager) ValidateRule(rule *SimulationRule) error {
 		}
 	}
 
+	// 验证属性间的依赖关系（引用的属性必须已配置且不存在循环依赖）
+	identifiers := make([]string, 0, len(rule.SimulationConfig))
+	for identifier := range rule.SimulationConfig {
+		identifiers = append(identifiers, identifier)
+	}
+	sort.Strings(identifiers)
+	if _, err := SortPropertiesByDependency(identifiers, rule.SimulationConfig); err != nil {
+		return err
+	}
+
 	// 验证事件配置
 	for _, event := range rule.Events {
 		if event.Identifier == "" {
@@ -155,6 +287,32 @@ func (m *RuleManager) ValidateRule(rule *SimulationRule) error {
 		if event.Cooldown < 0 {
 			return fmt.Errorf("事件[%s]冷却时间不能为负数", event.Identifier)
 		}
+		if event.TriggerCondition != "" {
+			if err := validateTriggerCondition(event.TriggerCondition, rule.SimulationConfig); err != nil {
+				return fmt.Errorf("事件[%s]触发条件无效: %v", event.Identifier, err)
+			}
+		}
+		if event.Stochastic != nil {
+			if event.TriggerCondition != "" || event.ClearCondition != "" {
+				return fmt.Errorf("事件[%s]配置了stochastic时不能配置triggerCondition和clearCondition", event.Identifier)
+			}
+			if err := validateStochasticConfig(*event.Stochastic); err != nil {
+				return fmt.Errorf("事件[%s]随机触发配置无效: %v", event.Identifier, err)
+			}
+		}
+		if event.Sustain < 0 || event.SustainCycles < 0 {
+			return fmt.Errorf("事件[%s]的sustain和sustainCycles不能为负数", event.Identifier)
+		}
+		if event.ClearCondition != "" {
+			if err := validateTriggerCondition(event.ClearCondition, rule.SimulationConfig); err != nil {
+				return fmt.Errorf("事件[%s]恢复条件无效: %v", event.Identifier, err)
+			}
+		}
+		for param, paramRule := range event.Params {
+			if err := m.validateEventParamRule(paramRule, rule.SimulationConfig); err != nil {
+				return fmt.Errorf("事件[%s]的参数[%s]配置无效: %v", event.Identifier, param, err)
+			}
+		}
 	}
 
 	// 验证服务配置
@@ -166,6 +324,24 @@ func (m *RuleManager) ValidateRule(rule *SimulationRule) error {
 		if err := m.validateServiceConfig(service); err != nil {
 			return fmt.Errorf("服务[%s]配置无效: %v", identifier, err)
 		}
+
+		for property, effect := range service.Effects {
+			if _, exists := rule.SimulationConfig[property]; !exists {
+				return fmt.Errorf("服务[%s]的effects引用了未配置的属性: %s", identifier, property)
+			}
+			if err := validateServiceEffect(effect); err != nil {
+				return fmt.Errorf("服务[%s]对属性[%s]的effects配置无效: %v", identifier, property, err)
+			}
+		}
+
+		for output, outputRule := range service.Outputs {
+			if outputRule.Method != "property" {
+				continue
+			}
+			if _, exists := rule.SimulationConfig[outputRule.Property]; !exists {
+				return fmt.Errorf("服务[%s]的输出参数[%s]引用了未配置的属性: %s", identifier, output, outputRule.Property)
+			}
+		}
 	}
 
 	return nil
@@ -173,7 +349,7 @@ func (m *RuleManager) ValidateRule(rule *SimulationRule) error {
 
 // validatePropertyConfig 验证属性配置
 func (m *RuleManager) validatePropertyConfig(config PropertySimConfig) error {
-	validMethods := []string{"randomRange", "wave", "accumulate", "increase", "enum", "enumPick", "fixed"}
+	validMethods := []string{"randomRange", "randomWalk", "wave", "profile", "replay", "expression", "firstOrderLag", "accumulate", "increase", "enum", "enumPick", "fixed", "timestamp", "struct", "array"}
 	
 	valid := false
 	for _, method := range validMethods {
@@ -199,6 +375,26 @@ func (m *RuleManager) validatePropertyConfig(config PropertySimConfig) error {
 			return fmt.Errorf("min值不能大于等于max值")
 		}
 		
+	case "randomWalk":
+		if config.Min == "" || config.Max == "" || config.Step == "" {
+			return fmt.Errorf("randomWalk方法需要min、max和step参数")
+		}
+		minVal, _ := config.Min.Float64()
+		maxVal, _ := config.Max.Float64()
+		if minVal >= maxVal {
+			return fmt.Errorf("min值不能大于等于max值")
+		}
+		stepVal, _ := config.Step.Float64()
+		if stepVal <= 0 {
+			return fmt.Errorf("randomWalk方法的step必须大于0")
+		}
+		if config.Start != "" {
+			startVal, _ := config.Start.Float64()
+			if startVal < minVal || startVal > maxVal {
+				return fmt.Errorf("start值必须在min和max之间")
+			}
+		}
+
 	case "wave":
 		if config.Min == "" || config.Max == "" || config.Amplitude == "" {
 			return fmt.Errorf("wave方法需要min、max和amplitude参数")
@@ -207,6 +403,45 @@ func (m *RuleManager) validatePropertyConfig(config PropertySimConfig) error {
 			return fmt.Errorf("波形周期必须大于0")
 		}
 		
+	case "profile":
+		if config.Profile == nil {
+			return fmt.Errorf("profile方法需要profile参数")
+		}
+		if err := validateProfileConfig(*config.Profile); err != nil {
+			return fmt.Errorf("profile配置无效: %v", err)
+		}
+
+	case "replay":
+		if config.Replay == nil {
+			return fmt.Errorf("replay方法需要replay参数")
+		}
+		if err := validateReplayConfig(*config.Replay); err != nil {
+			return fmt.Errorf("replay配置无效: %v", err)
+		}
+
+	case "expression":
+		if config.Expression == "" {
+			return fmt.Errorf("expression方法需要expression参数")
+		}
+		expr, err := CompileExpression(config.Expression)
+		if err != nil {
+			return fmt.Errorf("表达式无效: %v", err)
+		}
+		if len(expr.HistoryWindows()) > 0 {
+			return fmt.Errorf("属性表达式不支持delta/slope/avg等趋势函数")
+		}
+
+	case "firstOrderLag":
+		if config.Target == "" {
+			return fmt.Errorf("firstOrderLag方法需要target参数")
+		}
+		if config.TimeConstant <= 0 {
+			return fmt.Errorf("firstOrderLag方法的timeConstant必须大于0")
+		}
+		if config.Disturbance < 0 {
+			return fmt.Errorf("disturbance不能为负数")
+		}
+
 	case "accumulate", "increase":
 		if config.Step == "" {
 			return fmt.Errorf("%s方法需要step参数", config.Method)
@@ -219,19 +454,266 @@ func (m *RuleManager) validatePropertyConfig(config PropertySimConfig) error {
 		if config.SwitchProbability < 0 || config.SwitchProbability > 1 {
 			return fmt.Errorf("切换概率必须在0-1之间")
 		}
+		if err := m.validateEnumTransitions(config); err != nil {
+			return err
+		}
 		
 	case "fixed":
 		if config.Value == "" {
 			return fmt.Errorf("fixed方法需要value参数")
 		}
+
+	case "struct":
+		if len(config.Members) == 0 {
+			return fmt.Errorf("struct方法需要members参数")
+		}
+		for name, member := range config.Members {
+			if err := m.validatePropertyConfig(member); err != nil {
+				return fmt.Errorf("成员[%s]配置无效: %v", name, err)
+			}
+		}
+
+	case "array":
+		if config.Item == nil {
+			return fmt.Errorf("array方法需要item参数")
+		}
+		if config.Size <= 0 {
+			return fmt.Errorf("array方法的size必须大于0")
+		}
+		if err := m.validatePropertyConfig(*config.Item); err != nil {
+			return fmt.Errorf("元素配置无效: %v", err)
+		}
+	}
+
+	if config.Noise != nil {
+		if err := m.validateNoiseConfig(*config.Noise); err != nil {
+			return fmt.Errorf("噪声配置无效: %v", err)
+		}
+		if !supportsNoise(config) {
+			return fmt.Errorf("%s方法的结果不是连续数值，不能配置noise", config.Method)
+		}
+	}
+
+	if config.Fault != nil {
+		if err := validateFaultConfig(*config.Fault); err != nil {
+			return fmt.Errorf("故障注入配置无效: %v", err)
+		}
+	}
+
+	if config.OnSet != nil {
+		if err := validateSetConfig(*config.OnSet); err != nil {
+			return fmt.Errorf("onSet配置无效: %v", err)
+		}
 	}
 
 	return nil
 }
 
+// FaultConfig 定义传感器故障注入配置
+type FaultConfig struct {
+	Type        string         `json:"type"`                  // stuck(冻结在最后的值)、dropout(不上报)、spike(尖峰)、drift(线性漂移)
+	Probability float64        `json:"probability,omitempty"` // 每个上报周期开始故障的概率
+	Duration    int            `json:"duration,omitempty"`    // 故障持续秒数，0表示仅当前周期
+	Schedule    *FaultSchedule `json:"schedule,omitempty"`    // 按计划注入故障
+	Magnitude   float64        `json:"magnitude,omitempty"`   // spike故障的偏移幅度
+	DriftRate   float64        `json:"driftRate,omitempty"`   // drift故障每小时的漂移量
+}
+
+// FaultSchedule 定义故障注入计划，相对于模拟开始时间
+type FaultSchedule struct {
+	Start    int `json:"start"`              // 首次注入时间(秒)
+	Interval int `json:"interval,omitempty"` // 重复间隔(秒)，0表示只注入一次
+}
+
+// ProfileConfig 定义按时段变化的负载曲线配置
+type ProfileConfig struct {
+	Timezone      string                    `json:"timezone,omitempty"`      // 时区，如 Asia/Shanghai，默认本地时区
+	Interpolation string                    `json:"interpolation,omitempty"` // 插值方式: linear(默认) 或 step
+	Points        []ProfilePoint            `json:"points,omitempty"`        // 默认的每日控制点
+	Weekdays      map[string][]ProfilePoint `json:"weekdays,omitempty"`      // 按星期覆盖的控制点，如 saturday、sun
+}
+
+// ProfilePoint 定义曲线控制点
+type ProfilePoint struct {
+	Time  string      `json:"time"` // HH:MM 或 HH:MM:SS
+	Value json.Number `json:"value"`
+}
+
+// ReplayConfig 定义录制数据回放配置
+type ReplayConfig struct {
+	File        string `json:"file"`                  // CSV或JSONL文件路径，相对路径基于规则文件所在目录
+	Format      string `json:"format,omitempty"`      // csv 或 jsonl，默认按扩展名识别
+	Column      string `json:"column,omitempty"`      // 数据列/字段名，默认为属性标识符
+	TimeColumn  string `json:"timeColumn,omitempty"`  // 时间戳列/字段名
+	Align       string `json:"align,omitempty"`       // index(默认，每周期一个样本) 或 timestamp(按实际经过时间)
+	Loop        bool   `json:"loop,omitempty"`        // 播放结束后是否循环，否则保持最后一个值
+	Interpolate bool   `json:"interpolate,omitempty"` // 按timestamp对齐时是否在样本间线性插值
+}
+
+// validateEnumTransitions 验证枚举状态转移矩阵和停留时间
+func (m *RuleManager) validateEnumTransitions(config PropertySimConfig) error {
+	states := make(map[string]bool)
+	for _, value := range config.EnumValues {
+		states[value] = true
+	}
+
+	for from, row := range config.Transitions {
+		if !states[from] {
+			return fmt.Errorf("转移矩阵中的状态[%s]不在enumValues中", from)
+		}
+		sum := 0.0
+		for to, probability := range row {
+			if !states[to] {
+				return fmt.Errorf("转移矩阵中的状态[%s]不在enumValues中", to)
+			}
+			if probability < 0 || probability > 1 {
+				return fmt.Errorf("状态[%s]到[%s]的转移概率必须在0-1之间", from, to)
+			}
+			sum += probability
+		}
+		if math.Abs(sum-1) > 1e-6 {
+			return fmt.Errorf("状态[%s]的转移概率之和必须为1，当前为%g", from, sum)
+		}
+	}
+
+	// 配置了转移矩阵时每个状态都需要一行，吸收状态需显式配置为转移到自身
+	if len(config.Transitions) > 0 {
+		for _, value := range config.EnumValues {
+			if _, exists := config.Transitions[value]; !exists {
+				return fmt.Errorf("转移矩阵缺少状态[%s]的行，吸收状态请配置为{\"%s\": 1}", value, value)
+			}
+		}
+	}
+
+	for state, dwell := range config.DwellTimes {
+		if !states[state] {
+			return fmt.Errorf("停留时间中的状态[%s]不在enumValues中", state)
+		}
+		if dwell.Min < 0 || dwell.Max < 0 {
+			return fmt.Errorf("状态[%s]的停留时间不能为负数", state)
+		}
+		if dwell.Max > 0 && dwell.Max < dwell.Min {
+			return fmt.Errorf("状态[%s]的最长停留时间不能小于最短停留时间", state)
+		}
+	}
+
+	return nil
+}
+
+// supportsNoise 判断模拟方法的结果能否叠加噪声，枚举、时间戳和非数值的固定值不能叠加
+func supportsNoise(config PropertySimConfig) bool {
+	switch config.Method {
+	case "enum", "enumPick", "timestamp", "struct", "array":
+		return false
+	case "fixed":
+		_, err := config.Value.Float64()
+		return err == nil
+	}
+	return true
+}
+
+// validateNoiseConfig 验证噪声配置
+func (m *RuleManager) validateNoiseConfig(noise NoiseConfig) error {
+	if noise.Gaussian < 0 || noise.Uniform < 0 || noise.Resolution < 0 || noise.OutlierMagnitude < 0 {
+		return fmt.Errorf("噪声参数不能为负数")
+	}
+	if noise.OutlierProbability < 0 || noise.OutlierProbability > 1 {
+		return fmt.Errorf("离群值概率必须在0-1之间")
+	}
+	if noise.OutlierProbability > 0 && noise.OutlierMagnitude == 0 {
+		return fmt.Errorf("配置离群值概率时需要outlierMagnitude参数")
+	}
+	return nil
+}
+
+// propertyDependencies 获取属性配置引用的其他属性
+func propertyDependencies(config PropertySimConfig) ([]string, error) {
+	switch config.Method {
+	case "struct":
+		var deps []string
+		for _, member := range config.Members {
+			memberDeps, err := propertyDependencies(member)
+			if err != nil {
+				return nil, err
+			}
+			deps = append(deps, memberDeps...)
+		}
+		return deps, nil
+	case "array":
+		return propertyDependencies(*config.Item)
+	case "expression":
+		expr, err := CompileExpression(config.Expression)
+		if err != nil {
+			return nil, err
+		}
+		return expr.Identifiers(), nil
+	case "firstOrderLag":
+		// target为数值时是常量目标，否则引用其他属性
+		if _, err := strconv.ParseFloat(config.Target, 64); err != nil {
+			return []string{config.Target}, nil
+		}
+	}
+	return nil, nil
+}
+
+// SortPropertiesByDependency 按依赖关系排序属性，被引用的属性排在引用者之前，存在循环依赖时返回错误
+func SortPropertiesByDependency(identifiers []string, configs map[string]PropertySimConfig) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	ordered := make([]string, 0, len(identifiers))
+
+	var visit func(identifier string, path []string) error
+	visit = func(identifier string, path []string) error {
+		switch state[identifier] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("属性存在循环依赖: %s", strings.Join(append(path, identifier), " -> "))
+		}
+
+		state[identifier] = visiting
+		deps, err := propertyDependencies(configs[identifier])
+		if err != nil {
+			return fmt.Errorf("属性[%s]配置无效: %v", identifier, err)
+		}
+		for _, dep := range deps {
+			// 引用struct成员或array元素(如gps.lat)时依赖其所属属性
+			if _, exists := configs[dep]; !exists {
+				dep = strings.SplitN(dep, ".", 2)[0]
+			}
+			if dep == identifier {
+				continue
+			}
+			if _, exists := configs[dep]; !exists {
+				return fmt.Errorf("属性[%s]引用了未配置的属性: %s", identifier, dep)
+			}
+			if err := visit(dep, append(path, identifier)); err != nil {
+				return err
+			}
+		}
+		state[identifier] = visited
+		ordered = append(ordered, identifier)
+		return nil
+	}
+
+	for _, identifier := range identifiers {
+		if _, exists := configs[identifier]; !exists {
+			continue
+		}
+		if err := visit(identifier, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
 // validateServiceConfig 验证服务配置
 func (m *RuleManager) validateServiceConfig(config ServiceSimConfig) error {
-	validStrategies := []string{"fixed", "random", "randomPick"}
+	validStrategies := []string{"fixed", "random", "randomPick", "weighted", "sequence", "conditional"}
 	
 	valid := false
 	for _, strategy := range validStrategies {
@@ -249,15 +731,96 @@ func (m *RuleManager) validateServiceConfig(config ServiceSimConfig) error {
 		return fmt.Errorf("至少需要一个可能的响应")
 	}
 
+	totalWeight := 0.0
 	for i, response := range config.PossibleResponses {
 		if response.Code < 100 || response.Code > 599 {
 			return fmt.Errorf("响应[%d]的状态码无效: %d", i, response.Code)
 		}
+		if response.Weight < 0 {
+			return fmt.Errorf("响应[%d]的权重不能为负数", i)
+		}
+		totalWeight += response.Weight
+		if response.Condition != "" {
+			if _, err := CompileExpression(response.Condition); err != nil {
+				return fmt.Errorf("响应[%d]的条件无效: %v", i, err)
+			}
+		}
+	}
+	if config.ResponseStrategy == "weighted" && totalWeight <= 0 {
+		return fmt.Errorf("weighted策略需要至少一个权重大于0的响应")
+	}
+
+	if response := config.InvalidParamsResponse; response != nil {
+		if response.Code < 100 || response.Code > 599 || IsSuccessCode(response.Code) {
+			return fmt.Errorf("invalidParamsResponse的状态码无效: %d", response.Code)
+		}
+	}
+
+	if async := config.Async; async != nil {
+		if async.ProgressInterval < 0 {
+			return fmt.Errorf("progressInterval不能为负数")
+		}
+		if async.ProgressEvent != "" && async.ProgressInterval == 0 {
+			return fmt.Errorf("配置progressEvent时需要大于0的progressInterval")
+		}
+	}
+
+	for output, rule := range config.Outputs {
+		if err := validateServiceOutputRule(rule); err != nil {
+			return fmt.Errorf("输出参数[%s]配置无效: %v", output, err)
+		}
 	}
 
+	if config.NoReplyProbability < 0 || config.NoReplyProbability > 1 {
+		return fmt.Errorf("noReplyProbability必须在0-1之间")
+	}
+
+	if config.Latency != nil {
+		if err := validateLatencyConfig(*config.Latency); err != nil {
+			return fmt.Errorf("延时配置无效: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// validateTriggerCondition 编译触发条件并检查引用的属性均已配置，struct成员和array元素按所属属性检查
+func validateTriggerCondition(condition string, properties map[string]PropertySimConfig) error {
+	expr, err := CompileExpression(condition)
+	if err != nil {
+		return err
+	}
+	for _, name := range expr.Identifiers() {
+		if _, exists := properties[name]; exists {
+			continue
+		}
+		if _, exists := properties[strings.SplitN(name, ".", 2)[0]]; !exists {
+			return fmt.Errorf("引用了未配置的属性: %s", name)
+		}
+	}
 	return nil
 }
 
+// validateServiceEffect 验证服务对属性的设置
+func validateServiceEffect(effect ServiceEffect) error {
+	if (effect.Value == nil) == (effect.Param == "") {
+		return fmt.Errorf("value和param必须且只能配置一个")
+	}
+	return validateSetConfig(SetConfig{
+		Mode:     effect.Mode,
+		Hold:     effect.Hold,
+		RampRate: effect.RampRate,
+	})
+}
+
+// ResolvePath 解析规则中引用的文件路径，相对路径基于规则文件所在目录
+func (r *SimulationRule) ResolvePath(path string) string {
+	if filepath.IsAbs(path) || r.sourceDir == "" {
+		return path
+	}
+	return filepath.Join(r.sourceDir, path)
+}
+
 // GetProductNameFromRuleFile 从规则文件名提取产品名称
 func GetProductNameFromRuleFile(filename string) string {
 	base := filepath.Base(filename)
@@ -272,4 +835,4 @@ func GetProductNameFromRuleFile(filename string) string {
 // GenerateRuleFileName 生成规则文件名
 func GenerateRuleFileName(productName string) string {
 	return fmt.Sprintf("rule_%s.json", productName)
-}
\ No newline at end of file
+}
@@ -0,0 +1,118 @@
+package simulator
+
+import (
+	"fmt"
+	"time"
+
+	"znb/iot-uplink-gen/tsl"
+)
+
+// validateServiceOutputRule 验证服务输出参数的生成规则
+func validateServiceOutputRule(output ServiceOutputRule) error {
+	switch output.Method {
+	case "fixed":
+		if output.Value == nil {
+			return fmt.Errorf("fixed方法需要value参数")
+		}
+	case "range":
+		if output.Max <= output.Min {
+			return fmt.Errorf("range方法需要max大于min")
+		}
+	case "param":
+		if output.Param == "" {
+			return fmt.Errorf("param方法需要param参数")
+		}
+	case "property":
+		if output.Property == "" {
+			return fmt.Errorf("property方法需要property参数")
+		}
+	default:
+		return fmt.Errorf("不支持的输出生成方法: %s", output.Method)
+	}
+	return nil
+}
+
+// findAction 查找TSL中定义的服务
+func (sd *SimulatedDevice) findAction(identifier string) *tsl.Action {
+	for i := range sd.tslModel.Actions {
+		if sd.tslModel.Actions[i].Identifier == identifier {
+			return &sd.tslModel.Actions[i]
+		}
+	}
+	return nil
+}
+
+// generateServiceOutput 按TSL中服务的输出参数定义生成回复数据，并按参数的数据类型转换
+func (sd *SimulatedDevice) generateServiceOutput(action *tsl.Action, config ServiceSimConfig, params map[string]interface{}, response ServiceResponse) map[string]interface{} {
+	sd.simMutex.Lock()
+	defer sd.simMutex.Unlock()
+
+	outputs := action.GetOutputData()
+	data := make(map[string]interface{}, len(outputs))
+	for _, output := range outputs {
+		dataType := output.GetDataType()
+
+		var value interface{}
+		if rule, exists := config.Outputs[output.Identifier]; exists {
+			value = sd.serviceOutputValue(rule, params)
+		} else {
+			value = defaultServiceOutputValue(dataType, response)
+		}
+		if value == nil {
+			sd.log(fmt.Sprintf("[%s] 服务[%s]的输出参数[%s]没有可用的值", sd.DeviceInfo.DeviceName, action.Identifier, output.Identifier))
+			continue
+		}
+
+		converted, err := sd.convertValue(value, dataType)
+		if err != nil {
+			sd.log(fmt.Sprintf("[%s] 服务[%s]的输出参数[%s]类型转换失败: %v", sd.DeviceInfo.DeviceName, action.Identifier, output.Identifier, err))
+		}
+		data[output.Identifier] = converted
+	}
+	return data
+}
+
+// serviceOutputValue 按输出规则生成值，调用方需持有simMutex
+func (sd *SimulatedDevice) serviceOutputValue(rule ServiceOutputRule, params map[string]interface{}) interface{} {
+	switch rule.Method {
+	case "fixed":
+		return rule.Value
+	case "range":
+		return rule.Min + sd.serviceSim.rng.Float64()*(rule.Max-rule.Min)
+	case "param":
+		return params[rule.Param]
+	case "property":
+		value, _ := sd.propertySim.GetLastValue(rule.Property)
+		return value
+	}
+	return nil
+}
+
+// defaultServiceOutputValue 未配置输出规则时的默认值，bool表示服务是否成功，text使用响应消息
+func defaultServiceOutputValue(dataType tsl.DataType, response ServiceResponse) interface{} {
+	switch dataType.Type {
+	case "bool":
+		return IsSuccessCode(response.Code)
+	case "int", "long", "float", "double":
+		return dataType.Specs.Min
+	case "text", "string":
+		return response.Msg
+	case "enum":
+		if keys := dataType.Specs.GetEnumKeys(); len(keys) > 0 {
+			return keys[0]
+		}
+	case "date":
+		return time.Now().UnixNano() / int64(time.Millisecond)
+	case "struct":
+		fields := make(map[string]interface{}, len(dataType.Members))
+		for _, member := range dataType.Members {
+			if value := defaultServiceOutputValue(member.GetDataType(), response); value != nil {
+				fields[member.Identifier] = value
+			}
+		}
+		return fields
+	case "array":
+		return []interface{}{}
+	}
+	return nil
+}
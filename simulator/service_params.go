@@ -0,0 +1,109 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+
+	"znb/iot-uplink-gen/tsl"
+)
+
+// defaultInvalidParamsResponse 未配置invalidParamsResponse时参数校验失败的响应，desc为校验错误信息
+var defaultInvalidParamsResponse = ServiceResponse{
+	Code: 400,
+	Msg:  "参数错误",
+}
+
+// ValidateServiceParams 按TSL服务的输入参数定义校验调用参数，optional中的参数可以缺省
+func ValidateServiceParams(params map[string]interface{}, inputs []tsl.ActionParam, optional []string) error {
+	optionalSet := make(map[string]bool, len(optional))
+	for _, identifier := range optional {
+		optionalSet[identifier] = true
+	}
+
+	for _, input := range inputs {
+		value, exists := params[input.Identifier]
+		if !exists || value == nil {
+			if optionalSet[input.Identifier] {
+				continue
+			}
+			return fmt.Errorf("缺少参数[%s]", input.Identifier)
+		}
+		if err := validateParamValue(value, input.GetDataType()); err != nil {
+			return fmt.Errorf("参数[%s]无效: %v", input.Identifier, err)
+		}
+	}
+	return nil
+}
+
+// validateParamValue 校验参数值的类型、范围和枚举取值
+func validateParamValue(value interface{}, dataType tsl.DataType) error {
+	specs := dataType.Specs
+
+	switch dataType.Type {
+	case "int", "long":
+		val, ok := toFloat64(value)
+		if !ok || val != math.Trunc(val) {
+			return fmt.Errorf("值 %v 不是有效的整数", value)
+		}
+		return validateSetRange(value, dataType)
+
+	case "float", "double":
+		return validateSetRange(value, dataType)
+
+	case "bool":
+		_, err := convertBoolValue(value, specs)
+		return err
+
+	case "enum":
+		key := fmt.Sprintf("%v", value)
+		if _, exists := specs.GetEnumItems()[key]; !exists {
+			return fmt.Errorf("值 %v 不在枚举定义中", value)
+		}
+
+	case "text", "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("值 %v 不是字符串", value)
+		}
+		if specs.Length > 0 && len([]rune(str)) > specs.Length {
+			return fmt.Errorf("长度超过%d", specs.Length)
+		}
+
+	case "date":
+		_, err := convertDateValue(value)
+		return err
+
+	case "struct":
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("struct类型的值必须是对象: %v", value)
+		}
+		for _, member := range dataType.Members {
+			field, exists := fields[member.Identifier]
+			if !exists {
+				continue
+			}
+			if err := validateParamValue(field, member.GetDataType()); err != nil {
+				return fmt.Errorf("成员[%s]: %v", member.Identifier, err)
+			}
+		}
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("array类型的值必须是数组: %v", value)
+		}
+		if specs.Size > 0 && len(items) > specs.Size {
+			return fmt.Errorf("元素个数超过%d", specs.Size)
+		}
+		if specs.Item == nil {
+			return nil
+		}
+		for i, item := range items {
+			if err := validateParamValue(item, *specs.Item); err != nil {
+				return fmt.Errorf("元素[%d]: %v", i, err)
+			}
+		}
+	}
+	return nil
+}
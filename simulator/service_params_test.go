@@ -0,0 +1,70 @@
+package simulator
+
+import (
+	"testing"
+
+	"znb/iot-uplink-gen/tsl"
+)
+
+func TestValidateParamValue(t *testing.T) {
+	intType := tsl.DataType{Type: "int", Specs: tsl.DataSpecs{Min: 0, Max: 100}}
+	floatType := tsl.DataType{Type: "float", Specs: tsl.DataSpecs{Min: 16, Max: 30}}
+	boolType := tsl.DataType{Type: "bool", Specs: tsl.DataSpecs{True: "开", False: "关"}}
+	enumType := tsl.DataType{Type: "enum", Specs: tsl.DataSpecs{Enum: `{"0":"制冷","1":"制热"}`}}
+	textType := tsl.DataType{Type: "text", Specs: tsl.DataSpecs{Length: 4}}
+	structType := tsl.DataType{Type: "struct", Members: []tsl.StructMember{
+		{Identifier: "level", DataType: intType},
+		{Identifier: "label", DataType: textType},
+	}}
+	arrayType := tsl.DataType{Type: "array", Specs: tsl.DataSpecs{Size: 2, Item: &intType}}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		dataType tsl.DataType
+		wantErr  bool
+	}{
+		{name: "整数", value: float64(42), dataType: intType},
+		{name: "整数字符串", value: "42", dataType: intType},
+		{name: "整数有小数", value: 4.5, dataType: intType, wantErr: true},
+		{name: "整数超出范围", value: float64(101), dataType: intType, wantErr: true},
+		{name: "整数类型错误", value: "abc", dataType: intType, wantErr: true},
+		{name: "浮点数", value: 24.5, dataType: floatType},
+		{name: "浮点数边界", value: float64(30), dataType: floatType},
+		{name: "浮点数低于下限", value: 15.9, dataType: floatType, wantErr: true},
+		{name: "bool数值", value: float64(1), dataType: boolType},
+		{name: "bool布尔值", value: false, dataType: boolType},
+		{name: "bool显示文本", value: "开", dataType: boolType},
+		{name: "bool无效值", value: "maybe", dataType: boolType, wantErr: true},
+		{name: "枚举键", value: "1", dataType: enumType},
+		{name: "枚举数值键", value: float64(0), dataType: enumType},
+		{name: "枚举无效键", value: "2", dataType: enumType, wantErr: true},
+		{name: "文本", value: "自动", dataType: textType},
+		{name: "文本按字符计算长度", value: "自动模式", dataType: textType},
+		{name: "文本超长", value: "自动模式1", dataType: textType, wantErr: true},
+		{name: "文本类型错误", value: float64(1), dataType: textType, wantErr: true},
+		{name: "日期毫秒", value: float64(1700000000000), dataType: tsl.DataType{Type: "date"}},
+		{name: "日期RFC3339", value: "2024-01-02T03:04:05Z", dataType: tsl.DataType{Type: "date"}},
+		{name: "日期无效", value: "yesterday", dataType: tsl.DataType{Type: "date"}, wantErr: true},
+		{name: "struct", value: map[string]interface{}{"level": float64(3), "label": "ok"}, dataType: structType},
+		{name: "struct成员可缺省", value: map[string]interface{}{"level": float64(3)}, dataType: structType},
+		{name: "struct成员无效", value: map[string]interface{}{"level": float64(300)}, dataType: structType, wantErr: true},
+		{name: "struct类型错误", value: "x", dataType: structType, wantErr: true},
+		{name: "array", value: []interface{}{float64(1), float64(2)}, dataType: arrayType},
+		{name: "array元素过多", value: []interface{}{float64(1), float64(2), float64(3)}, dataType: arrayType, wantErr: true},
+		{name: "array元素无效", value: []interface{}{float64(1), 2.5}, dataType: arrayType, wantErr: true},
+		{name: "array类型错误", value: float64(1), dataType: arrayType, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateParamValue(tt.value, tt.dataType)
+			if tt.wantErr && err == nil {
+				t.Fatalf("validateParamValue(%v) 应返回错误", tt.value)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("validateParamValue(%v) 返回错误: %v", tt.value, err)
+			}
+		})
+	}
+}
@@ -1,27 +1,49 @@
 package simulator
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
+	"strings"
 	"time"
 )
 
+// defaultServiceLatency 未配置延时分布时的服务处理延时
+const defaultServiceLatency = 3 * time.Second
+
+
+// z99 标准正态分布的99分位数
+const z99 = 2.326
+
 // ServiceSimulator 服务模拟器
 type ServiceSimulator struct {
-	// 可以添加服务相关的内部状态
+	rng            *rand.Rand             // 设备级随机数源
+	sequenceIndex  map[string]int         // sequence策略下各服务的下一个响应序号
+	conditionCache map[string]*Expression // 已编译的响应条件
 }
 
 // NewServiceSimulator 创建服务模拟器
-func NewServiceSimulator() *ServiceSimulator {
-	return &ServiceSimulator{}
+func NewServiceSimulator(rng *rand.Rand) *ServiceSimulator {
+	return &ServiceSimulator{
+		rng:            rng,
+		sequenceIndex:  make(map[string]int),
+		conditionCache: make(map[string]*Expression),
+	}
 }
 
-// SimulateServiceResponse 根据配置生成服务响应
-func (ss *ServiceSimulator) SimulateServiceResponse(config ServiceSimConfig) ServiceResponse {
+// SimulateServiceResponse 根据配置生成服务响应，params用于conditional策略的条件匹配
+func (ss *ServiceSimulator) SimulateServiceResponse(identifier string, config ServiceSimConfig, params map[string]interface{}) ServiceResponse {
 	switch config.ResponseStrategy {
 	case "fixed":
 		return ss.getFixedResponse(config)
 	case "random", "randomPick":
 		return ss.getRandomResponse(config)
+	case "weighted":
+		return ss.getWeightedResponse(config)
+	case "sequence":
+		return ss.getSequenceResponse(identifier, config)
+	case "conditional":
+		return ss.getConditionalResponse(config, params)
 	default:
 		// 默认返回成功响应
 		return ServiceResponse{
@@ -32,6 +54,11 @@ func (ss *ServiceSimulator) SimulateServiceResponse(config ServiceSimConfig) Ser
 	}
 }
 
+// IsSuccessCode 判断响应码是否表示成功(2xx)
+func IsSuccessCode(code int) bool {
+	return code >= 200 && code < 300
+}
+
 // getFixedResponse 获取固定响应（总是返回第一个响应）
 func (ss *ServiceSimulator) getFixedResponse(config ServiceSimConfig) ServiceResponse {
 	if len(config.PossibleResponses) > 0 {
@@ -55,10 +82,156 @@ func (ss *ServiceSimulator) getRandomResponse(config ServiceSimConfig) ServiceRe
 		}
 	}
 	
-	idx := rand.Intn(len(config.PossibleResponses))
+	idx := ss.rng.Intn(len(config.PossibleResponses))
 	return config.PossibleResponses[idx]
 }
 
+// getWeightedResponse 按权重随机选择响应
+func (ss *ServiceSimulator) getWeightedResponse(config ServiceSimConfig) ServiceResponse {
+	total := 0.0
+	for _, response := range config.PossibleResponses {
+		total += response.Weight
+	}
+	if total <= 0 {
+		return ss.getRandomResponse(config)
+	}
+
+	target := ss.rng.Float64() * total
+	for _, response := range config.PossibleResponses {
+		if response.Weight <= 0 {
+			continue
+		}
+		target -= response.Weight
+		if target < 0 {
+			return response
+		}
+	}
+	return config.PossibleResponses[len(config.PossibleResponses)-1]
+}
+
+// getSequenceResponse 按配置顺序轮流返回响应
+func (ss *ServiceSimulator) getSequenceResponse(identifier string, config ServiceSimConfig) ServiceResponse {
+	if len(config.PossibleResponses) == 0 {
+		return ss.getFixedResponse(config)
+	}
+
+	idx := ss.sequenceIndex[identifier] % len(config.PossibleResponses)
+	ss.sequenceIndex[identifier] = idx + 1
+	return config.PossibleResponses[idx]
+}
+
+// getConditionalResponse 返回第一个条件与输入参数匹配的响应，都不匹配时返回第一个未配置条件的响应
+func (ss *ServiceSimulator) getConditionalResponse(config ServiceSimConfig, params map[string]interface{}) ServiceResponse {
+	resolve := func(name string) (interface{}, bool) {
+		return lookupPropertyPath(params, name)
+	}
+
+	var fallback *ServiceResponse
+	for i, response := range config.PossibleResponses {
+		if strings.TrimSpace(response.Condition) == "" {
+			if fallback == nil {
+				fallback = &config.PossibleResponses[i]
+			}
+			continue
+		}
+
+		expr, err := ss.compileCondition(response.Condition)
+		if err != nil {
+			continue
+		}
+		// 条件引用的参数不存在时视为不匹配
+		if matched, err := expr.EvaluateBool(resolve); err == nil && matched {
+			return response
+		}
+	}
+
+	if fallback != nil {
+		return *fallback
+	}
+	return ServiceResponse{
+		Code: 200,
+		Msg:  "ok",
+		Desc: "操作成功",
+	}
+}
+
+// compileCondition 编译并缓存响应条件
+func (ss *ServiceSimulator) compileCondition(condition string) (*Expression, error) {
+	if expr, exists := ss.conditionCache[condition]; exists {
+		return expr, nil
+	}
+	expr, err := CompileExpression(condition)
+	if err != nil {
+		return nil, err
+	}
+	ss.conditionCache[condition] = expr
+	return expr, nil
+}
+
+// ShouldNotReply 按noReplyProbability决定本次调用是否不回复
+func (ss *ServiceSimulator) ShouldNotReply(config ServiceSimConfig) bool {
+	return config.NoReplyProbability > 0 && ss.rng.Float64() < config.NoReplyProbability
+}
+
+// SampleLatency 按配置的分布抽取服务响应延时
+func (ss *ServiceSimulator) SampleLatency(latency *LatencyConfig) time.Duration {
+	if latency == nil {
+		return defaultServiceLatency
+	}
+
+	var ms float64
+	switch latency.Distribution {
+	case "", "fixed":
+		ms = latency.Value
+	case "uniform":
+		ms = latency.Min + ss.rng.Float64()*(latency.Max-latency.Min)
+	case "normal":
+		ms = latency.Mean + ss.rng.NormFloat64()*latency.StdDev
+	case "longTail":
+		// 对数正态分布，由中位数和99分位数确定形状
+		sigma := math.Log(latency.P99/latency.Median) / z99
+		ms = latency.Median * math.Exp(ss.rng.NormFloat64()*sigma)
+	}
+
+	ms = math.Max(ms, latency.Min)
+	if latency.Max > 0 {
+		ms = math.Min(ms, latency.Max)
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// validateLatencyConfig 验证服务延时配置
+func validateLatencyConfig(latency LatencyConfig) error {
+	if latency.Min < 0 || latency.Max < 0 {
+		return fmt.Errorf("min和max不能为负数")
+	}
+	if latency.Max > 0 && latency.Max < latency.Min {
+		return fmt.Errorf("max不能小于min")
+	}
+
+	switch latency.Distribution {
+	case "", "fixed":
+		if latency.Value < 0 {
+			return fmt.Errorf("value不能为负数")
+		}
+	case "uniform":
+		if latency.Max <= latency.Min {
+			return fmt.Errorf("uniform分布需要max大于min")
+		}
+	case "normal":
+		if latency.Mean < 0 || latency.StdDev < 0 {
+			return fmt.Errorf("normal分布的mean和stddev不能为负数")
+		}
+	case "longTail":
+		if latency.Median <= 0 || latency.P99 <= latency.Median {
+			return fmt.Errorf("longTail分布需要大于0的median和大于median的p99")
+		}
+	default:
+		return fmt.Errorf("不支持的延时分布: %s", latency.Distribution)
+	}
+	return nil
+}
+
 // SimulateServiceDelay 模拟服务处理延时
 func (ss *ServiceSimulator) SimulateServiceDelay(minDelayMs, maxDelayMs int) {
 	if minDelayMs <= 0 && maxDelayMs <= 0 {
@@ -73,7 +246,7 @@ func (ss *ServiceSimulator) SimulateServiceDelay(minDelayMs, maxDelayMs int) {
 	if minDelayMs == maxDelayMs {
 		delayMs = minDelayMs
 	} else {
-		delayMs = minDelayMs + rand.Intn(maxDelayMs-minDelayMs)
+		delayMs = minDelayMs + ss.rng.Intn(maxDelayMs-minDelayMs)
 	}
 	
 	if delayMs > 0 {
@@ -124,6 +297,20 @@ func (ss *ServiceSimulator) CalculateSuccessRate(config ServiceSimConfig) float6
 		return 1.0 // 默认100%成功率
 	}
 	
+	// weighted策略按权重计算
+	if config.ResponseStrategy == "weighted" {
+		total, success := 0.0, 0.0
+		for _, response := range config.PossibleResponses {
+			total += response.Weight
+			if IsSuccessCode(response.Code) {
+				success += response.Weight
+			}
+		}
+		if total > 0 {
+			return success / total
+		}
+	}
+
 	successCount := 0
 	for _, response := range config.PossibleResponses {
 		if response.Code >= 200 && response.Code < 300 {
@@ -136,7 +323,7 @@ func (ss *ServiceSimulator) CalculateSuccessRate(config ServiceSimConfig) float6
 
 // GenerateResponseWithSuccessRate 根据指定成功率生成响应
 func (ss *ServiceSimulator) GenerateResponseWithSuccessRate(config ServiceSimConfig, successRate float64) ServiceResponse {
-	if rand.Float64() < successRate {
+	if ss.rng.Float64() < successRate {
 		// 返回成功响应
 		successResp := ss.GetSuccessResponse(config)
 		if successResp != nil {
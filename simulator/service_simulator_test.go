@@ -0,0 +1,37 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampleLatencyBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		latency *LatencyConfig
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "未配置", latency: nil, min: defaultServiceLatency, max: defaultServiceLatency},
+		{name: "固定", latency: &LatencyConfig{Value: 800}, min: 800 * time.Millisecond, max: 800 * time.Millisecond},
+		{name: "固定值低于下限", latency: &LatencyConfig{Distribution: "fixed", Value: 100, Min: 500}, min: 500 * time.Millisecond, max: 500 * time.Millisecond},
+		{name: "固定值超过上限", latency: &LatencyConfig{Distribution: "fixed", Value: 9000, Max: 2000}, min: 2 * time.Second, max: 2 * time.Second},
+		{name: "均匀分布", latency: &LatencyConfig{Distribution: "uniform", Min: 100, Max: 300}, min: 100 * time.Millisecond, max: 300 * time.Millisecond},
+		{name: "正态分布不为负", latency: &LatencyConfig{Distribution: "normal", Mean: 50, StdDev: 200}, min: 0, max: time.Hour},
+		{name: "正态分布限幅", latency: &LatencyConfig{Distribution: "normal", Mean: 1000, StdDev: 500, Min: 800, Max: 1200}, min: 800 * time.Millisecond, max: 1200 * time.Millisecond},
+		{name: "长尾分布限幅", latency: &LatencyConfig{Distribution: "longTail", Median: 300, P99: 8000, Max: 15000}, min: 0, max: 15 * time.Second},
+		{name: "长尾分布下限", latency: &LatencyConfig{Distribution: "longTail", Median: 300, P99: 8000, Min: 200}, min: 200 * time.Millisecond, max: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := NewServiceSimulator(newSeededRand(1))
+			for i := 0; i < 1000; i++ {
+				got := ss.SampleLatency(tt.latency)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("SampleLatency() = %v, 超出 [%v, %v]", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
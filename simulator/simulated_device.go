@@ -2,9 +2,12 @@ package simulator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math/rand"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,24 +22,34 @@ type SimulatedDevice struct {
 	core.BaseDevice
 
 	// TSL和规则
-	tslModel *tsl.TSLModel
-	rule     *SimulationRule
+	tslModel      *tsl.TSLModel
+	rule          *SimulationRule
+	propertyOrder []string                // 按依赖关系排序的属性生成顺序
+	propertyTypes map[string]tsl.DataType // 属性的TSL数据类型，上报前按此转换
 
 	// 模拟器组件
+	rng         *rand.Rand
+	seed        int64
 	propertySim *PropertySimulator
 	eventSim    *EventSimulator
 	serviceSim  *ServiceSimulator
 
 	// Framework引用
 	framework core.Framework
+	link      *mqttLink // 直接收发物模型报文的MQTT连接
 
 	// 运行时状态
 	running        bool
 	stopCh         chan struct{}
 	ticker         *time.Ticker
 	mutex          sync.RWMutex
+	simMutex       sync.Mutex // 保护模拟器组件的内部状态，模拟周期与状态保存互斥
 	lastReportTime time.Time
 
+	// 状态持久化
+	stateFile     string
+	lastStateSave time.Time
+
 	// 统计信息
 	stats SimulatorStats
 
@@ -52,11 +65,46 @@ type SimulatorStats struct {
 	ServiceCalls    int64 `json:"serviceCalls"`
 	Errors          int64 `json:"errors"`
 	StartTime       int64 `json:"startTime"`
+	Seed            int64 `json:"seed"`
+	FaultInjections int64 `json:"faultInjections"`
+	PropertySets    int64 `json:"propertySets"`
+	SetFailures     int64 `json:"setFailures"`
+
+	ServiceLatencyTotal  int64 `json:"serviceLatencyTotalMs"` // 已回复服务调用的累计延时(毫秒)
+	ServiceLatencyMax    int64 `json:"serviceLatencyMaxMs"`   // 服务调用的最大延时(毫秒)
+	ServiceNoReplies     int64 `json:"serviceNoReplies"`      // 模拟不回复的服务调用次数
+	InvalidServiceParams int64 `json:"invalidServiceParams"`  // 参数校验失败的服务调用次数
+
+	Alarms map[string]AlarmState `json:"alarms,omitempty"` // 告警模式事件的告警状态
 }
 
 // NewSimulatedDevice 创建模拟设备
 func NewSimulatedDevice(productKey, deviceName, deviceSecret string, tslModel *tsl.TSLModel, rule *SimulationRule) *SimulatedDevice {
-	return &SimulatedDevice{
+	// 按TSL中的顺序生成属性，派生属性排在其依赖的属性之后
+	identifiers := make([]string, 0, len(tslModel.Properties))
+	propertyTypes := make(map[string]tsl.DataType, len(tslModel.Properties))
+	for _, prop := range tslModel.Properties {
+		identifiers = append(identifiers, prop.Identifier)
+		propertyTypes[prop.Identifier] = prop.GetDataType()
+	}
+	propertyOrder, err := SortPropertiesByDependency(identifiers, rule.SimulationConfig)
+	if err != nil {
+		log.Printf("[%s] 属性依赖排序失败，使用TSL顺序: %v", deviceName, err)
+		propertyOrder = identifiers
+	}
+
+	// 规则中配置了种子时按设备派生，否则使用当前时间
+	seed := time.Now().UnixNano()
+	if rule.Seed != nil {
+		seed = DeriveDeviceSeed(*rule.Seed, productKey, deviceName)
+	}
+	rng := newSeededRand(seed)
+
+	// 预先编译事件条件，趋势函数引用的属性从第一个周期开始记录历史
+	eventSim := NewEventSimulator(rng)
+	eventSim.PrepareConditions(rule.Events)
+
+	sd := &SimulatedDevice{
 		BaseDevice: core.BaseDevice{
 			DeviceInfo: core.DeviceInfo{
 				ProductKey:   productKey,
@@ -68,15 +116,33 @@ func NewSimulatedDevice(productKey, deviceName, deviceSecret string, tslModel *t
 		},
 		tslModel:       tslModel,
 		rule:           rule,
-		propertySim:    NewPropertySimulator(),
-		eventSim:       NewEventSimulator(),
-		serviceSim:     NewServiceSimulator(),
+		propertyOrder:  propertyOrder,
+		propertyTypes:  propertyTypes,
+		rng:            rng,
+		seed:           seed,
+		propertySim:    NewPropertySimulator(rng),
+		eventSim:       eventSim,
+		serviceSim:     NewServiceSimulator(rng),
 		stopCh:         make(chan struct{}),
 		uploadInterval: 30 * time.Second, // 默认30秒上报间隔
 		stats: SimulatorStats{
 			StartTime: time.Now().Unix(),
+			Seed:      seed,
 		},
 	}
+	sd.propertySim.SetFaultCallback(sd.onFaultTransition)
+	return sd
+}
+
+// onFaultTransition 记录属性（含struct成员）故障的开始和结束
+func (sd *SimulatedDevice) onFaultTransition(identifier string, fault FaultConfig, transition string) {
+	switch transition {
+	case FaultStarted:
+		atomic.AddInt64(&sd.stats.FaultInjections, 1)
+		sd.log(fmt.Sprintf("[%s] 属性[%s]注入%s故障，持续%d秒", sd.DeviceInfo.DeviceName, identifier, fault.Type, fault.Duration))
+	case FaultEnded:
+		sd.log(fmt.Sprintf("[%s] 属性[%s]%s故障结束", sd.DeviceInfo.DeviceName, identifier, fault.Type))
+	}
 }
 
 // SetFramework 设置框架引用
@@ -89,6 +155,88 @@ func (sd *SimulatedDevice) SetUploadInterval(interval time.Duration) {
 	sd.uploadInterval = interval
 }
 
+// SetSeed 设置随机种子，需在模拟启动前调用，用于复现指定的运行
+func (sd *SimulatedDevice) SetSeed(seed int64) {
+	sd.mutex.Lock()
+	defer sd.mutex.Unlock()
+
+	sd.seed = seed
+	sd.stats.Seed = seed
+	sd.rng.Seed(seed)
+}
+
+// GetSeed 获取当前使用的随机种子
+func (sd *SimulatedDevice) GetSeed() int64 {
+	sd.mutex.RLock()
+	defer sd.mutex.RUnlock()
+	return sd.seed
+}
+
+// EnableStatePersistence 启用模拟状态持久化，fresh为false时从状态文件恢复上次保存的状态
+func (sd *SimulatedDevice) EnableStatePersistence(stateFile string, fresh bool) error {
+	sd.simMutex.Lock()
+	defer sd.simMutex.Unlock()
+
+	sd.stateFile = stateFile
+	if fresh {
+		return nil
+	}
+
+	state, err := loadDeviceState(stateFile)
+	if err != nil || state == nil {
+		return err
+	}
+
+	for identifier, value := range state.PropertyStates {
+		sd.propertySim.SetState(identifier, value)
+	}
+	for identifier, t := range state.UpdateTimes {
+		sd.propertySim.SetUpdateTime(identifier, t)
+	}
+	for identifier, timestamp := range state.EventTriggers {
+		sd.eventSim.SetEventTriggerTime(identifier, timestamp)
+	}
+	for identifier, alarm := range state.Alarms {
+		sd.eventSim.SetAlarmState(identifier, alarm)
+	}
+	for identifier, schedule := range state.Stochastic {
+		sd.eventSim.SetStochasticSchedule(identifier, schedule)
+	}
+
+	sd.log(fmt.Sprintf("[%s] 已恢复模拟状态: %d个属性状态，保存于%s", sd.DeviceInfo.DeviceName, len(state.PropertyStates), state.SavedAt.Format(time.RFC3339)))
+	return nil
+}
+
+// SaveState 保存当前模拟状态到状态文件，未启用持久化时直接返回
+func (sd *SimulatedDevice) SaveState() error {
+	sd.simMutex.Lock()
+	defer sd.simMutex.Unlock()
+	return sd.saveState()
+}
+
+// saveState 保存模拟状态，调用方需持有simMutex
+func (sd *SimulatedDevice) saveState() error {
+	if sd.stateFile == "" {
+		return nil
+	}
+
+	state := &DeviceState{
+		ProductKey:     sd.DeviceInfo.ProductKey,
+		DeviceName:     sd.DeviceInfo.DeviceName,
+		SavedAt:        time.Now(),
+		PropertyStates: sd.propertySim.GetAllStates(),
+		UpdateTimes:    sd.propertySim.GetAllUpdateTimes(),
+		EventTriggers:  sd.eventSim.GetEventTriggerHistory(),
+		Alarms:         sd.eventSim.GetAlarmStates(),
+		Stochastic:     sd.eventSim.GetStochasticSchedules(),
+	}
+	if err := saveDeviceState(sd.stateFile, state); err != nil {
+		return err
+	}
+	sd.lastStateSave = state.SavedAt
+	return nil
+}
+
 // SetLogCallback 设置日志回调
 func (sd *SimulatedDevice) SetLogCallback(callback func(string)) {
 	sd.logCallback = callback
@@ -97,6 +245,7 @@ func (sd *SimulatedDevice) SetLogCallback(callback func(string)) {
 // OnInitialize 设备初始化
 func (sd *SimulatedDevice) OnInitialize(ctx context.Context) error {
 	sd.log(fmt.Sprintf("[%s] 初始化模拟设备: %s", sd.DeviceInfo.DeviceName, sd.rule.ProductName))
+	sd.log(fmt.Sprintf("[%s] 随机种子: %d", sd.DeviceInfo.DeviceName, sd.GetSeed()))
 
 	// 注册TSL定义的属性
 	sd.log(fmt.Sprintf("[%s] 注册属性...", sd.DeviceInfo.DeviceName))
@@ -107,8 +256,9 @@ func (sd *SimulatedDevice) OnInitialize(ctx context.Context) error {
 			}
 		}(prop.Identifier)
 
+		// 未声明accessMode的属性按可读写处理
 		var setter func(interface{}) error = nil
-		if prop.AccessMode == "rw" {
+		if prop.AccessMode == "rw" || prop.AccessMode == "" {
 			setter = func(identifier string) func(interface{}) error {
 				return func(value interface{}) error {
 					return sd.setPropertyValue(identifier, value)
@@ -142,6 +292,7 @@ func (sd *SimulatedDevice) OnInitialize(ctx context.Context) error {
 // OnConnect 设备连接
 func (sd *SimulatedDevice) OnConnect(ctx context.Context) error {
 	sd.log(fmt.Sprintf("[%s] 设备已连接到IoT平台", sd.DeviceInfo.DeviceName))
+	sd.attachMQTT()
 
 	// 启动模拟器
 	sd.startSimulation()
@@ -173,23 +324,18 @@ func (sd *SimulatedDevice) OnDestroy(ctx context.Context) error {
 func (sd *SimulatedDevice) OnPropertySet(property core.Property) error {
 	sd.log(fmt.Sprintf("[%s] 接收到属性设置请求: %s = %v", sd.DeviceInfo.DeviceName, property.Name, property.Value))
 
-	// 验证属性是否存在于TSL中
-	found := false
+	// 验证属性是否存在于TSL中且可写
 	for _, prop := range sd.tslModel.Properties {
 		if prop.Identifier == property.Name {
-			found = true
-			break
+			if prop.AccessMode == "r" {
+				return fmt.Errorf("属性[%s]为只读属性", property.Name)
+			}
+			// 设定值由RegisterProperty注册的setter处理
+			return nil
 		}
 	}
 
-	if !found {
-		return fmt.Errorf("属性[%s]不存在于TSL定义中", property.Name)
-	}
-
-	// 这里可以根据需要实现属性设置的业务逻辑
-	// 对于模拟器，我们可能需要更新模拟器的内部状态
-
-	return nil
+	return fmt.Errorf("属性[%s]不存在于TSL定义中", property.Name)
 }
 
 // OnServiceInvoke 处理服务调用
@@ -268,6 +414,10 @@ func (sd *SimulatedDevice) stopSimulation() {
 		close(sd.stopCh)
 	}
 
+	if err := sd.SaveState(); err != nil {
+		sd.log(fmt.Sprintf("[%s] 保存模拟状态失败: %v", sd.DeviceInfo.DeviceName, err))
+	}
+
 	sd.log(fmt.Sprintf("[%s] 模拟器已停止", sd.DeviceInfo.DeviceName))
 }
 
@@ -285,6 +435,9 @@ func (sd *SimulatedDevice) simulationLoop() {
 
 // runSimulationCycle 运行一个模拟周期
 func (sd *SimulatedDevice) runSimulationCycle() {
+	sd.simMutex.Lock()
+	defer sd.simMutex.Unlock()
+
 	// 1. 生成属性数据
 	propertyData := sd.generatePropertyData()
 
@@ -297,22 +450,42 @@ func (sd *SimulatedDevice) runSimulationCycle() {
 	// 更新统计
 	atomic.AddInt64(&sd.stats.PropertyUpdates, 1)
 	sd.lastReportTime = time.Now()
+
+	// 4. 定期保存模拟状态
+	if sd.stateFile != "" && time.Since(sd.lastStateSave) >= defaultStateSaveInterval {
+		if err := sd.saveState(); err != nil {
+			sd.log(fmt.Sprintf("[%s] 保存模拟状态失败: %v", sd.DeviceInfo.DeviceName, err))
+		}
+	}
 }
 
 // generatePropertyData 生成属性数据
 func (sd *SimulatedDevice) generatePropertyData() map[string]interface{} {
 	properties := make(map[string]interface{})
 
-	for _, prop := range sd.tslModel.Properties {
+	for _, identifier := range sd.propertyOrder {
 		// 检查是否有对应的模拟配置
-		config, exists := sd.rule.SimulationConfig[prop.Identifier]
+		config, exists := sd.rule.SimulationConfig[identifier]
 		if !exists {
 			continue
 		}
 
-		// 生成模拟值
-		value := sd.propertySim.SimulateValue(prop.Identifier, config)
-		properties[prop.Identifier] = value
+		// 生成模拟值（含注入的传感器故障），没有可用的值时本周期不上报
+		value := sd.propertySim.SimulateValue(identifier, config)
+		if value == nil {
+			continue
+		}
+
+		// 按TSL数据类型转换，不合规的值记录日志后原样上报
+		if dataType, exists := sd.propertyTypes[identifier]; exists {
+			converted, err := sd.convertValue(value, dataType)
+			if err != nil {
+				sd.log(fmt.Sprintf("[%s] 属性[%s]类型转换失败: %v", sd.DeviceInfo.DeviceName, identifier, err))
+			}
+			value = converted
+		}
+
+		properties[identifier] = value
 	}
 
 	return properties
@@ -320,27 +493,74 @@ func (sd *SimulatedDevice) generatePropertyData() map[string]interface{} {
 
 // checkAndTriggerEvents 检查并触发事件
 func (sd *SimulatedDevice) checkAndTriggerEvents(propertyData map[string]interface{}) {
+	sd.eventSim.RecordHistory(propertyData)
+	resolve := propertyResolver(propertyData)
+
 	for _, eventConfig := range sd.rule.Events {
-		if triggered, eventData := sd.eventSim.CheckEventTrigger(eventConfig, propertyData); triggered {
-			// 发布事件
-			if err := sd.framework.ReportEvent(eventConfig.Identifier, eventData); err != nil {
-				sd.log(fmt.Sprintf("[%s] 发布事件[%s]失败: %v", sd.DeviceInfo.DeviceName, eventConfig.Identifier, err))
-				atomic.AddInt64(&sd.stats.Errors, 1)
-			} else {
-				sd.log(fmt.Sprintf("[%s] 事件[%s]已触发", sd.DeviceInfo.DeviceName, eventConfig.Identifier))
-				atomic.AddInt64(&sd.stats.EventTriggers, 1)
+		// 随机事件与属性值无关
+		if eventConfig.Stochastic != nil {
+			identifier, count, eventData := sd.eventSim.CheckStochasticEvent(eventConfig)
+			for i := 0; i < count; i++ {
+				sd.publishEvent(identifier, eventParamRules(eventConfig, identifier), eventData, resolve)
 			}
+			continue
+		}
+
+		// 告警中的事件检查是否恢复
+		if cleared, eventData := sd.eventSim.CheckEventRecovery(eventConfig, propertyData); cleared {
+			sd.log(fmt.Sprintf("[%s] 事件[%s]告警已恢复", sd.DeviceInfo.DeviceName, eventConfig.Identifier))
+			if eventConfig.RecoveryEvent != "" {
+				sd.publishEvent(eventConfig.RecoveryEvent, nil, eventData, resolve)
+			}
+			continue
+		}
+
+		if triggered, eventData := sd.eventSim.CheckEventTrigger(eventConfig, propertyData); triggered {
+			sd.publishEvent(eventConfig.Identifier, eventConfig.Params, eventData, resolve)
 		}
 	}
 }
 
-// reportProperties 上报属性
-func (sd *SimulatedDevice) reportProperties(properties map[string]interface{}) {
+// eventParamRules 获取上报事件的参数规则，params只用于事件本身，不用于恢复事件
+func eventParamRules(config EventSimConfig, identifier string) map[string]EventParamRule {
+	if identifier != config.Identifier {
+		return nil
+	}
+	return config.Params
+}
+
+// publishEvent 按TSL的事件定义构造参数并发布事件，调用方需持有simMutex
+func (sd *SimulatedDevice) publishEvent(identifier string, rules map[string]EventParamRule, values map[string]interface{}, resolve exprResolver) {
+	eventData := sd.buildEventData(identifier, rules, values, resolve)
+	if err := sd.framework.ReportEvent(identifier, eventData); err != nil {
+		sd.log(fmt.Sprintf("[%s] 发布事件[%s]失败: %v", sd.DeviceInfo.DeviceName, identifier, err))
+		atomic.AddInt64(&sd.stats.Errors, 1)
+	} else {
+		sd.log(fmt.Sprintf("[%s] 事件[%s]已触发", sd.DeviceInfo.DeviceName, identifier))
+		atomic.AddInt64(&sd.stats.EventTriggers, 1)
+	}
+}
+
+// reportProperties 上报属性，只上报TSL中定义的属性，规则中的辅助属性仅参与计算
+func (sd *SimulatedDevice) reportProperties(propertyData map[string]interface{}) {
+	properties := make(map[string]interface{}, len(propertyData))
+	for identifier, value := range propertyData {
+		if _, exists := sd.propertyTypes[identifier]; exists {
+			properties[identifier] = value
+		}
+	}
 	if len(properties) == 0 {
 		return
 	}
 
-	if err := sd.framework.ReportProperties(properties); err != nil {
+	// SDK会把属性值转换为字符串，有直接连接时按TSL类型上报
+	var err error
+	if link := sd.directLink(); link != nil {
+		err = link.postProperties(properties)
+	} else {
+		err = sd.framework.ReportProperties(properties)
+	}
+	if err != nil {
 		sd.log(fmt.Sprintf("[%s] 上报属性失败: %v", sd.DeviceInfo.DeviceName, err))
 		atomic.AddInt64(&sd.stats.Errors, 1)
 	} else {
@@ -350,34 +570,106 @@ func (sd *SimulatedDevice) reportProperties(properties map[string]interface{}) {
 
 // reportCurrentStatus 立即上报当前状态
 func (sd *SimulatedDevice) reportCurrentStatus() {
+	sd.simMutex.Lock()
+	defer sd.simMutex.Unlock()
+
 	propertyData := sd.generatePropertyData()
 	sd.reportProperties(propertyData)
 }
 
-// getPropertyValue 获取属性值
+// getPropertyValue 获取属性最近一次模拟的值，平台读取属性不推进模拟
 func (sd *SimulatedDevice) getPropertyValue(identifier string) interface{} {
-	config, exists := sd.rule.SimulationConfig[identifier]
+	sd.simMutex.Lock()
+	value, exists := sd.propertySim.GetLastValue(identifier)
+	sd.simMutex.Unlock()
 	if !exists {
 		return nil
 	}
 
-	return sd.propertySim.SimulateValue(identifier, config)
+	if dataType, ok := sd.propertyTypes[identifier]; ok {
+		converted, err := sd.convertValue(value, dataType)
+		if err != nil {
+			sd.log(fmt.Sprintf("[%s] 属性[%s]类型转换失败: %v", sd.DeviceInfo.DeviceName, identifier, err))
+		}
+		value = converted
+	}
+	return value
 }
 
-// setPropertyValue 设置属性值
+// errSetFailure 按onSet.failureRate模拟的设置失败
+var errSetFailure = errors.New("设置失败")
+
+// setPropertyValue 设置属性值，设定值按onSet配置替代模拟值，并可模拟设置延时和失败
 func (sd *SimulatedDevice) setPropertyValue(identifier string, value interface{}) error {
-	// 验证属性值
+	// 设定值只按TSL规格校验，规则的模拟范围不限制设定值
 	config, exists := sd.rule.SimulationConfig[identifier]
-	if exists {
-		if err := sd.propertySim.ValidatePropertyValue(value, config); err != nil {
+	if dataType, ok := sd.propertyTypes[identifier]; ok {
+		if err := validateSetRange(value, dataType); err != nil {
 			return fmt.Errorf("属性值验证失败: %v", err)
 		}
 	}
 
+	var set SetConfig
+	if exists && config.OnSet != nil {
+		set = *config.OnSet
+	}
+
+	// 模拟设置响应延时
+	if set.Latency > 0 {
+		time.Sleep(time.Duration(set.Latency) * time.Millisecond)
+	}
+
+	// 模拟设置失败
+	if set.FailureRate > 0 {
+		sd.simMutex.Lock()
+		failed := sd.rng.Float64() < set.FailureRate
+		sd.simMutex.Unlock()
+		if failed {
+			atomic.AddInt64(&sd.stats.SetFailures, 1)
+			sd.log(fmt.Sprintf("[%s] 属性[%s]模拟设置失败", sd.DeviceInfo.DeviceName, identifier))
+			return fmt.Errorf("属性[%s]%w", identifier, errSetFailure)
+		}
+	}
+
+	sd.simMutex.Lock()
+	sd.propertySim.SetOverride(identifier, value, set)
+	sd.simMutex.Unlock()
+
+	atomic.AddInt64(&sd.stats.PropertySets, 1)
 	sd.log(fmt.Sprintf("[%s] 属性[%s]设置为: %v", sd.DeviceInfo.DeviceName, identifier, value))
 	return nil
 }
 
+// convertValue 按TSL数据类型转换值，规则配置boolFormat为bool时bool类型以true/false上报
+func (sd *SimulatedDevice) convertValue(value interface{}, dataType tsl.DataType) (interface{}, error) {
+	converted, err := ConvertToTSLType(value, dataType)
+	if sd.rule.BoolFormat == "bool" {
+		converted = boolsToJSON(converted, dataType)
+	}
+	return converted, err
+}
+
+// validateSetRange 检查设定的数值是否在TSL规格范围内
+func validateSetRange(value interface{}, dataType tsl.DataType) error {
+	switch dataType.Type {
+	case "int", "long", "float", "double":
+	default:
+		return nil
+	}
+	val, ok := toFloat64(value)
+	if !ok {
+		return fmt.Errorf("值不是有效的数字: %v", value)
+	}
+	specs := dataType.Specs
+	if specs.Max > specs.Min && (val < specs.Min || val > specs.Max) {
+		return fmt.Errorf("值 %v 超出TSL范围 [%v, %v]", value, specs.Min, specs.Max)
+	}
+	return nil
+}
+
+// errNoReply 按noReplyProbability模拟的不回复
+var errNoReply = errors.New("设备不回复")
+
 // handleService 处理服务调用
 func (sd *SimulatedDevice) handleService(identifier string, params map[string]interface{}) (interface{}, error) {
 	sd.log(fmt.Sprintf("[%s] 处理服务[%s]调用, 参数: %v", sd.DeviceInfo.DeviceName, identifier, params))
@@ -388,21 +680,135 @@ func (sd *SimulatedDevice) handleService(identifier string, params map[string]in
 		return nil, fmt.Errorf("服务[%s]未配置", identifier)
 	}
 
-	// 模拟服务处理延时（固定3秒）
-	time.Sleep(3 * time.Second)
+	sd.simMutex.Lock()
+	noReply := sd.serviceSim.ShouldNotReply(config)
+	latency := sd.serviceSim.SampleLatency(config.Latency)
+	sd.simMutex.Unlock()
 
-	// 生成响应
-	response := sd.serviceSim.SimulateServiceResponse(config)
+	// 模拟设备不回复，由调用方丢弃请求
+	if noReply {
+		atomic.AddInt64(&sd.stats.ServiceNoReplies, 1)
+		sd.log(fmt.Sprintf("[%s] 服务[%s]模拟不回复", sd.DeviceInfo.DeviceName, identifier))
+		return nil, errNoReply
+	}
+
+	// 异步服务立即确认，延时后通过事件上报执行结果
+	action := sd.findAction(identifier)
+	if action != nil && action.CallType == "async" {
+		// 参数无效时直接回复错误响应
+		if err := ValidateServiceParams(params, action.GetInputData(), config.OptionalParams); err != nil {
+			payload, _ := sd.executeService(identifier, action, config, params)
+			return payload, nil
+		}
+		sd.log(fmt.Sprintf("[%s] 异步服务[%s]已确认, %dms后上报结果", sd.DeviceInfo.DeviceName, identifier, latency.Milliseconds()))
+		go sd.runAsyncService(identifier, action, config, params, latency)
+		return map[string]interface{}{
+			"code": 200,
+			"msg":  "accepted",
+			"desc": "异步服务处理中",
+		}, nil
+	}
+
+	// 模拟服务处理延时
+	sd.log(fmt.Sprintf("[%s] 服务[%s]模拟延时: %dms", sd.DeviceInfo.DeviceName, identifier, latency.Milliseconds()))
+	select {
+	case <-time.After(latency):
+	case <-sd.stopCh:
+		return nil, fmt.Errorf("模拟器已停止")
+	}
+	sd.recordServiceLatency(latency)
+
+	payload, _ := sd.executeService(identifier, action, config, params)
+	return payload, nil
+}
+
+// executeService 校验参数并生成服务响应，返回回复数据和选中的响应
+func (sd *SimulatedDevice) executeService(identifier string, action *tsl.Action, config ServiceSimConfig, params map[string]interface{}) (map[string]interface{}, ServiceResponse) {
+	// 按TSL输入参数定义校验参数，TSL中未定义的服务不校验
+	var paramsErr error
+	if action != nil {
+		paramsErr = ValidateServiceParams(params, action.GetInputData(), config.OptionalParams)
+	}
+
+	var response ServiceResponse
+	if paramsErr != nil {
+		atomic.AddInt64(&sd.stats.InvalidServiceParams, 1)
+		sd.log(fmt.Sprintf("[%s] 服务[%s]参数校验失败: %v", sd.DeviceInfo.DeviceName, identifier, paramsErr))
+		response = defaultInvalidParamsResponse
+		if config.InvalidParamsResponse != nil {
+			response = *config.InvalidParamsResponse
+		}
+		if response.Desc == "" {
+			response.Desc = paramsErr.Error()
+		}
+	} else {
+		// 生成响应
+		sd.simMutex.Lock()
+		response = sd.serviceSim.SimulateServiceResponse(identifier, config, params)
+		sd.simMutex.Unlock()
+	}
 
 	atomic.AddInt64(&sd.stats.ServiceCalls, 1)
 
 	sd.log(fmt.Sprintf("[%s] 服务[%s]响应: code=%d, msg=%s", sd.DeviceInfo.DeviceName, identifier, response.Code, response.Msg))
 
+	// 仅在服务成功时改变设备状态，TSL定义了输出参数时按输出参数生成回复数据
+	if IsSuccessCode(response.Code) {
+		sd.applyServiceEffects(identifier, config, params)
+		if action != nil && len(action.GetOutputData()) > 0 {
+			return sd.generateServiceOutput(action, config, params, response), response
+		}
+	}
+
 	return map[string]interface{}{
 		"code": response.Code,
 		"msg":  response.Msg,
 		"desc": response.Desc,
-	}, nil
+	}, response
+}
+
+// recordServiceLatency 累计服务延时并更新最大延时
+func (sd *SimulatedDevice) recordServiceLatency(latency time.Duration) {
+	ms := latency.Milliseconds()
+	atomic.AddInt64(&sd.stats.ServiceLatencyTotal, ms)
+	for {
+		current := atomic.LoadInt64(&sd.stats.ServiceLatencyMax)
+		if ms <= current || atomic.CompareAndSwapInt64(&sd.stats.ServiceLatencyMax, current, ms) {
+			return
+		}
+	}
+}
+
+// applyServiceEffects 按服务的effects配置设置属性值，设定值在后续上报中生效
+func (sd *SimulatedDevice) applyServiceEffects(identifier string, config ServiceSimConfig, params map[string]interface{}) {
+	properties := make([]string, 0, len(config.Effects))
+	for property := range config.Effects {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	sd.simMutex.Lock()
+	defer sd.simMutex.Unlock()
+
+	for _, property := range properties {
+		effect := config.Effects[property]
+		value := effect.Value
+		if effect.Param != "" {
+			paramValue, exists := params[effect.Param]
+			if !exists {
+				sd.log(fmt.Sprintf("[%s] 服务[%s]缺少参数[%s]，跳过对属性[%s]的设置", sd.DeviceInfo.DeviceName, identifier, effect.Param, property))
+				continue
+			}
+			value = paramValue
+		}
+
+		sd.propertySim.SetOverride(property, value, SetConfig{
+			Mode:     effect.Mode,
+			Hold:     effect.Hold,
+			RampRate: effect.RampRate,
+		})
+		sd.log(fmt.Sprintf("[%s] 服务[%s]设置属性[%s]: %v", sd.DeviceInfo.DeviceName, identifier, property, value))
+	}
 }
 
 // IsRunning 检查模拟器是否运行
@@ -420,7 +826,28 @@ func (sd *SimulatedDevice) GetStats() SimulatorStats {
 		ServiceCalls:    atomic.LoadInt64(&sd.stats.ServiceCalls),
 		Errors:          atomic.LoadInt64(&sd.stats.Errors),
 		StartTime:       sd.stats.StartTime,
+		Seed:            sd.GetSeed(),
+		FaultInjections: atomic.LoadInt64(&sd.stats.FaultInjections),
+		PropertySets:    atomic.LoadInt64(&sd.stats.PropertySets),
+		SetFailures:     atomic.LoadInt64(&sd.stats.SetFailures),
+
+		ServiceLatencyTotal:  atomic.LoadInt64(&sd.stats.ServiceLatencyTotal),
+		ServiceLatencyMax:    atomic.LoadInt64(&sd.stats.ServiceLatencyMax),
+		ServiceNoReplies:     atomic.LoadInt64(&sd.stats.ServiceNoReplies),
+		InvalidServiceParams: atomic.LoadInt64(&sd.stats.InvalidServiceParams),
+		Alarms:               sd.getAlarmStates(),
+	}
+}
+
+// getAlarmStates 获取事件的告警状态，没有告警模式事件时返回nil
+func (sd *SimulatedDevice) getAlarmStates() map[string]AlarmState {
+	sd.simMutex.Lock()
+	defer sd.simMutex.Unlock()
+	alarms := sd.eventSim.GetAlarmStates()
+	if len(alarms) == 0 {
+		return nil
 	}
+	return alarms
 }
 
 // GetProductName 获取产品名称
@@ -0,0 +1,85 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultStateSaveInterval 模拟状态的默认保存间隔
+const defaultStateSaveInterval = 60 * time.Second
+
+// DeviceState 设备模拟状态快照，用于进程重启后恢复累加值、随机游走位置和事件冷却等
+type DeviceState struct {
+	ProductKey     string                        `json:"productKey"`
+	DeviceName     string                        `json:"deviceName"`
+	SavedAt        time.Time                     `json:"savedAt"`
+	PropertyStates map[string]float64            `json:"propertyStates"`
+	UpdateTimes    map[string]time.Time          `json:"updateTimes,omitempty"`
+	EventTriggers  map[string]int64              `json:"eventTriggers,omitempty"`
+	Alarms         map[string]AlarmState         `json:"alarms,omitempty"`
+	Stochastic     map[string]StochasticSchedule `json:"stochastic,omitempty"`
+}
+
+// DeviceStateFile 获取设备在状态目录下的状态文件路径
+func DeviceStateFile(stateDir, productKey, deviceName string) string {
+	return filepath.Join(stateDir, fmt.Sprintf("%s_%s.state.json", productKey, deviceName))
+}
+
+// ClearStateDir 删除状态目录下保存的所有设备状态，用于全新启动，未配置状态目录时不做处理
+func ClearStateDir(stateDir string) error {
+	if stateDir == "" {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(stateDir, "*.state.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("删除状态文件失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// loadDeviceState 从文件加载设备状态，文件不存在时返回nil
+func loadDeviceState(path string) (*DeviceState, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取状态文件失败: %v", err)
+	}
+
+	var state DeviceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("解析状态文件失败: %v", err)
+	}
+	return &state, nil
+}
+
+// saveDeviceState 保存设备状态，先写临时文件再重命名，避免中断时留下不完整的文件
+func saveDeviceState(path string, state *DeviceState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建状态目录失败: %v", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化状态失败: %v", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("写入状态文件失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("写入状态文件失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package simulator
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxStochasticArrivals 一次检查最多处理的泊松到达次数，避免长时间停机后一次补发过多事件
+const maxStochasticArrivals = 100
+
+// StochasticSchedule 随机事件的调度状态，随模拟状态保存以便重启后按原计划继续
+type StochasticSchedule struct {
+	Next time.Time `json:"next"`           // 下一次状态变化的时间
+	Down bool      `json:"down,omitempty"` // mtbf模式下是否处于故障中
+}
+
+// validateStochasticConfig 验证随机事件配置
+func validateStochasticConfig(config StochasticConfig) error {
+	switch config.Type {
+	case "poisson":
+		if config.MeanInterval <= 0 {
+			return fmt.Errorf("poisson需要大于0的meanInterval参数")
+		}
+	case "mtbf":
+		if config.MTBF <= 0 || config.MTTR <= 0 {
+			return fmt.Errorf("mtbf需要大于0的mtbf和mttr参数")
+		}
+	default:
+		return fmt.Errorf("不支持的随机事件类型: %s", config.Type)
+	}
+	return nil
+}
+
+// CheckStochasticEvent 检查随机事件是否发生，返回需要上报的事件标识符、上报次数和数据，没有事件时标识符为空。
+// poisson模式按指数分布的间隔触发，上次检查以来到达的每一次都上报；mtbf模式在故障和修复之间交替，修复时上报recoveryEvent
+func (es *EventSimulator) CheckStochasticEvent(config EventSimConfig) (string, int, map[string]interface{}) {
+	stochastic := config.Stochastic
+	if stochastic == nil {
+		return "", 0, nil
+	}
+
+	now := time.Now()
+	state, exists := es.stochastic[config.Identifier]
+	if !exists {
+		state = &StochasticSchedule{}
+		// 从恢复的告警状态继续，故障中的事件等待修复
+		if alarm, ok := es.alarms[config.Identifier]; ok && stochastic.Type == "mtbf" {
+			state.Down = alarm.Active
+		}
+		state.Next = now.Add(es.stochasticInterval(*stochastic, state.Down))
+		es.stochastic[config.Identifier] = state
+		return "", 0, nil
+	}
+
+	if now.Before(state.Next) {
+		return "", 0, nil
+	}
+
+	if stochastic.Type == "poisson" {
+		count := es.poissonArrivals(config, state, now)
+		if count == 0 {
+			return "", 0, nil
+		}
+		return config.Identifier, count, map[string]interface{}{}
+	}
+
+	// mtbf模式在故障和修复之间切换
+	alarm := es.alarmState(config.Identifier)
+	state.Down = !state.Down
+	state.Next = now.Add(es.stochasticInterval(*stochastic, state.Down))
+	if state.Down {
+		alarm.Active = true
+		alarm.RaisedAt = now.Unix()
+		alarm.RaiseCount++
+		es.lastTriggerTime[config.Identifier] = now.Unix()
+		return config.Identifier, 1, map[string]interface{}{}
+	}
+
+	alarm.Active = false
+	alarm.ClearedAt = now.Unix()
+	if config.RecoveryEvent == "" {
+		return "", 0, nil
+	}
+	return config.RecoveryEvent, 1, map[string]interface{}{
+		"downtime": alarm.ClearedAt - alarm.RaisedAt,
+	}
+}
+
+// poissonArrivals 统计到now为止到达的事件次数，下一次到达时间从上一次到达时间起算，
+// 冷却时间按到达时间计算，超过maxStochasticArrivals时从now重新抽取下一次到达时间
+func (es *EventSimulator) poissonArrivals(config EventSimConfig, state *StochasticSchedule, now time.Time) int {
+	count := 0
+	for arrivals := 0; !now.Before(state.Next); arrivals++ {
+		if arrivals >= maxStochasticArrivals {
+			state.Next = now.Add(es.stochasticInterval(*config.Stochastic, false))
+			break
+		}
+
+		arrival := state.Next
+		state.Next = arrival.Add(es.stochasticInterval(*config.Stochastic, false))
+		if last, exists := es.lastTriggerTime[config.Identifier]; exists && arrival.Unix()-last < int64(config.Cooldown) {
+			continue
+		}
+		es.lastTriggerTime[config.Identifier] = arrival.Unix()
+		count++
+	}
+	return count
+}
+
+// GetStochasticSchedules 获取所有随机事件的调度状态
+func (es *EventSimulator) GetStochasticSchedules() map[string]StochasticSchedule {
+	schedules := make(map[string]StochasticSchedule, len(es.stochastic))
+	for identifier, state := range es.stochastic {
+		schedules[identifier] = *state
+	}
+	return schedules
+}
+
+// SetStochasticSchedule 设置随机事件的调度状态（用于状态恢复）
+func (es *EventSimulator) SetStochasticSchedule(identifier string, schedule StochasticSchedule) {
+	state := schedule
+	es.stochastic[identifier] = &state
+}
+
+// stochasticInterval 按指数分布抽取到下一次状态变化的间隔，down表示当前处于故障中
+func (es *EventSimulator) stochasticInterval(config StochasticConfig, down bool) time.Duration {
+	mean := config.MeanInterval
+	if config.Type == "mtbf" {
+		mean = config.MTBF
+		if down {
+			mean = config.MTTR
+		}
+	}
+	return time.Duration(es.rng.ExpFloat64() * mean * float64(time.Second))
+}
@@ -0,0 +1,218 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"znb/iot-uplink-gen/tsl"
+)
+
+// ConvertToTSLType 将模拟生成的值转换为TSL数据类型，并按规格约束取值范围和精度
+func ConvertToTSLType(value interface{}, dataType tsl.DataType) (interface{}, error) {
+	specs := dataType.Specs
+
+	switch dataType.Type {
+	case "int", "long":
+		val, ok := toFloat64(value)
+		if !ok {
+			return value, fmt.Errorf("无法将值 %v 转换为%s", value, dataType.Type)
+		}
+		// 按步长对齐，步长以最小值为起点
+		if specs.Step > 1 {
+			val = specs.Min + math.Round((val-specs.Min)/specs.Step)*specs.Step
+		}
+		val = clampToSpecs(math.Round(val), specs)
+		return int64(val), nil
+
+	case "float", "double":
+		val, ok := toFloat64(value)
+		if !ok {
+			return value, fmt.Errorf("无法将值 %v 转换为%s", value, dataType.Type)
+		}
+		val = clampToSpecs(val, specs)
+		decimalPlaces := -1
+		if specs.Accuracy > 0 {
+			decimalPlaces = specs.Accuracy
+		} else if specs.Step > 0 {
+			decimalPlaces = countDecimalPlaces(strconv.FormatFloat(specs.Step, 'f', -1, 64))
+		}
+		if decimalPlaces >= 0 {
+			scale := math.Pow10(decimalPlaces)
+			val = math.Round(val*scale) / scale
+		}
+		return val, nil
+
+	case "bool":
+		return convertBoolValue(value, specs)
+
+	case "enum":
+		return convertEnumValue(value, specs)
+
+	case "date":
+		return convertDateValue(value)
+
+	case "struct":
+		return convertStructValue(value, dataType)
+
+	case "array":
+		return convertArrayValue(value, dataType)
+
+	case "text", "string":
+		str := fmt.Sprintf("%v", value)
+		if specs.Length > 0 && len([]rune(str)) > specs.Length {
+			str = string([]rune(str)[:specs.Length])
+		}
+		return str, nil
+	}
+
+	return value, nil
+}
+
+// convertDateValue 将日期转换为UTC毫秒时间戳字符串，支持毫秒数值和RFC3339格式
+func convertDateValue(value interface{}) (interface{}, error) {
+	if ms, ok := toFloat64(value); ok {
+		return strconv.FormatInt(int64(ms), 10), nil
+	}
+	if str, ok := value.(string); ok {
+		if t, err := time.Parse(time.RFC3339, str); err == nil {
+			return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10), nil
+		}
+	}
+	return value, fmt.Errorf("无法将值 %v 转换为date", value)
+}
+
+// convertStructValue 按成员定义逐个转换struct的成员值
+func convertStructValue(value interface{}, dataType tsl.DataType) (interface{}, error) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return value, fmt.Errorf("struct类型的值必须是对象: %v", value)
+	}
+
+	result := make(map[string]interface{}, len(fields))
+	for name, field := range fields {
+		result[name] = field
+	}
+
+	var firstErr error
+	for _, member := range dataType.Members {
+		field, exists := fields[member.Identifier]
+		if !exists {
+			continue
+		}
+		converted, err := ConvertToTSLType(field, member.GetDataType())
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("成员[%s]: %v", member.Identifier, err)
+		}
+		result[member.Identifier] = converted
+	}
+	return result, firstErr
+}
+
+// convertArrayValue 按元素类型转换array的各个元素，超出size的元素被截断
+func convertArrayValue(value interface{}, dataType tsl.DataType) (interface{}, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return value, fmt.Errorf("array类型的值必须是数组: %v", value)
+	}
+	if size := dataType.Specs.Size; size > 0 && len(items) > size {
+		items = items[:size]
+	}
+
+	result := make([]interface{}, len(items))
+	var firstErr error
+	for i, item := range items {
+		result[i] = item
+		if dataType.Specs.Item == nil {
+			continue
+		}
+		converted, err := ConvertToTSLType(item, *dataType.Specs.Item)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("元素[%d]: %v", i, err)
+		}
+		result[i] = converted
+	}
+	return result, firstErr
+}
+
+// clampToSpecs 将数值限制在规格的最小值和最大值之间，未配置范围时不限制
+func clampToSpecs(val float64, specs tsl.DataSpecs) float64 {
+	if specs.Max <= specs.Min {
+		return val
+	}
+	return math.Max(specs.Min, math.Min(specs.Max, val))
+}
+
+// convertBoolValue 将布尔值转换为0/1，支持true/false、0/1以及规格中定义的显示文本
+func convertBoolValue(value interface{}, specs tsl.DataSpecs) (interface{}, error) {
+	if b, ok := value.(bool); ok {
+		if b {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	str := strings.TrimSpace(fmt.Sprintf("%v", value))
+	switch {
+	case str == "1" || strings.EqualFold(str, "true") || (specs.True != "" && str == specs.True):
+		return 1, nil
+	case str == "0" || strings.EqualFold(str, "false") || (specs.False != "" && str == specs.False):
+		return 0, nil
+	}
+	return value, fmt.Errorf("无法将值 %v 转换为bool", value)
+}
+
+// boolsToJSON 将转换后的bool值(0/1)改为true/false，包括struct成员和array元素
+func boolsToJSON(value interface{}, dataType tsl.DataType) interface{} {
+	switch dataType.Type {
+	case "bool":
+		if n, ok := value.(int); ok {
+			return n == 1
+		}
+	case "struct":
+		if fields, ok := value.(map[string]interface{}); ok {
+			for _, member := range dataType.Members {
+				if field, exists := fields[member.Identifier]; exists {
+					fields[member.Identifier] = boolsToJSON(field, member.GetDataType())
+				}
+			}
+		}
+	case "array":
+		if items, ok := value.([]interface{}); ok && dataType.Specs.Item != nil {
+			for i, item := range items {
+				items[i] = boolsToJSON(item, *dataType.Specs.Item)
+			}
+		}
+	}
+	return value
+}
+
+// convertEnumValue 将枚举值转换为枚举键，值可以是键本身或其显示文本
+func convertEnumValue(value interface{}, specs tsl.DataSpecs) (interface{}, error) {
+	str := strings.TrimSpace(fmt.Sprintf("%v", value))
+	items := specs.GetEnumItems()
+
+	key := str
+	if len(items) > 0 {
+		if _, exists := items[str]; !exists {
+			found := false
+			for k, label := range items {
+				if label == str {
+					key = k
+					found = true
+					break
+				}
+			}
+			if !found {
+				return value, fmt.Errorf("值 %v 不在枚举定义中", value)
+			}
+		}
+	}
+
+	if n, err := strconv.ParseInt(key, 10, 64); err == nil {
+		return n, nil
+	}
+	return key, nil
+}
@@ -1,6 +1,7 @@
 package tsl
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -12,10 +13,31 @@ import (
 
 // TSLModel 定义完整的 TSL 结构
 type TSLModel struct {
-	Version    string     `json:"version"`
-	Properties []Property `json:"properties"`
-	Events     []Event    `json:"events"`
-	Actions    []Action   `json:"actions"`
+	Schema          string          `json:"schema,omitempty"`
+	Profile         *Profile        `json:"profile,omitempty"`
+	Version         string          `json:"version"`
+	FunctionBlockID string          `json:"functionBlockId,omitempty"` // 自定义功能模块的TSL，标识符按"模块:标识符"上报
+	Properties      []Property      `json:"properties"`
+	Events          []Event         `json:"events"`
+	Actions         []Action        `json:"actions"`
+	Services        []Action        `json:"services,omitempty"`       // 阿里云格式的服务定义，规范化后合并到Actions
+	FunctionBlocks  []FunctionBlock `json:"functionBlocks,omitempty"` // 自定义功能模块，规范化后合并到默认模块
+}
+
+// Profile 定义TSL所属的产品信息
+type Profile struct {
+	Version    string `json:"version,omitempty"`
+	ProductKey string `json:"productKey"`
+}
+
+// FunctionBlock 定义自定义功能模块
+type FunctionBlock struct {
+	FunctionBlockID   string     `json:"functionBlockId"`
+	FunctionBlockName string     `json:"functionBlockName,omitempty"`
+	Properties        []Property `json:"properties"`
+	Events            []Event    `json:"events"`
+	Actions           []Action   `json:"actions,omitempty"`
+	Services          []Action   `json:"services,omitempty"`
 }
 
 // Property 定义属性结构
@@ -135,23 +157,85 @@ func (ap *ActionParam) GetDataType() DataType {
 
 // DataType 定义数据类型结构
 type DataType struct {
-	Type  string    `json:"type"`
-	Specs DataSpecs `json:"specs"`
+	Type    string         `json:"type"`
+	Specs   DataSpecs      `json:"specs"`
+	Members []StructMember `json:"-"` // struct类型的成员，JSON中以specs数组表示
+}
+
+// StructMember 定义struct类型的成员
+type StructMember struct {
+	Identifier string   `json:"identifier"`
+	Name       string   `json:"name"`
+	DataType   DataType `json:"dataType"`
+	DataType2  DataType `json:"data_type"` // 支持下划线格式
+}
+
+// GetDataType 获取成员的数据类型，支持两种字段格式
+func (sm *StructMember) GetDataType() DataType {
+	if sm.DataType2.Type != "" {
+		return sm.DataType2
+	}
+	return sm.DataType
+}
+
+// UnmarshalJSON 解析数据类型，struct类型的specs为成员数组，其他类型为规格对象
+func (dt *DataType) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type  string          `json:"type"`
+		Specs json.RawMessage `json:"specs"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*dt = DataType{Type: raw.Type}
+	specs := bytes.TrimSpace(raw.Specs)
+	if len(specs) == 0 || string(specs) == "null" {
+		return nil
+	}
+	if specs[0] == '[' {
+		if err := json.Unmarshal(specs, &dt.Members); err != nil {
+			return fmt.Errorf("解析struct成员失败: %v", err)
+		}
+		return nil
+	}
+	return json.Unmarshal(specs, &dt.Specs)
+}
+
+// MarshalJSON 序列化数据类型，struct类型的成员写回specs数组
+func (dt DataType) MarshalJSON() ([]byte, error) {
+	if dt.Type == "struct" {
+		members := dt.Members
+		if members == nil {
+			members = []StructMember{}
+		}
+		return json.Marshal(struct {
+			Type  string         `json:"type"`
+			Specs []StructMember `json:"specs"`
+		}{dt.Type, members})
+	}
+	return json.Marshal(struct {
+		Type  string    `json:"type"`
+		Specs DataSpecs `json:"specs"`
+	}{dt.Type, dt.Specs})
 }
 
 // DataSpecs 定义数据规格结构
 type DataSpecs struct {
-	Length    int     `json:"length"`
-	Unit      string  `json:"unit"`
-	UnitName  string  `json:"unitName"`
-	Min       float64 `json:"min"`
-	Max       float64 `json:"max"`
-	Step      float64 `json:"step"`
-	Accuracy  int     `json:"accuracy"`
-	Enum      string  `json:"enum"`
-	True      string  `json:"true"`
-	False     string  `json:"false"`
-	EnumValue string  `json:"enumValue"`
+	Length    int               `json:"length"`
+	Unit      string            `json:"unit"`
+	UnitName  string            `json:"unitName"`
+	Min       float64           `json:"min"`
+	Max       float64           `json:"max"`
+	Step      float64           `json:"step"`
+	Accuracy  int               `json:"accuracy"`
+	Enum      string            `json:"enum"`
+	True      string            `json:"true"`
+	False     string            `json:"false"`
+	EnumValue string            `json:"enumValue"`
+	Size      int               `json:"size,omitempty"` // array类型的元素个数
+	Item      *DataType         `json:"item,omitempty"` // array类型的元素类型
+	EnumItems map[string]string `json:"-"`              // enum/bool的取值与显示文本，JSON中为{"0":"关闭","1":"开启"}
 }
 
 // TSLManager TSL管理器
@@ -188,6 +272,7 @@ func (m *TSLManager) LoadTSL(filename string) (*TSLModel, error) {
 	if err := json.Unmarshal(data, &tslModel); err != nil {
 		return nil, fmt.Errorf("解析TSL失败: %v", err)
 	}
+	tslModel.Normalize()
 
 	return &tslModel, nil
 }
@@ -275,7 +360,7 @@ func (m *TSLManager) ValidateTSL(model *TSLModel) error {
 
 // validateDataType 验证数据类型
 func (m *TSLManager) validateDataType(dt DataType) error {
-	validTypes := []string{"int", "long", "float", "double", "bool", "text", "string", "enum"}
+	validTypes := []string{"int", "long", "float", "double", "bool", "text", "string", "enum", "date", "struct", "array"}
 	
 	valid := false
 	for _, validType := range validTypes {
@@ -296,6 +381,41 @@ func (m *TSLManager) validateDataType(dt DataType) error {
 		}
 	}
 
+	switch dt.Type {
+	case "struct":
+		if len(dt.Members) == 0 {
+			return fmt.Errorf("struct类型至少需要一个成员")
+		}
+		for _, member := range dt.Members {
+			if member.Identifier == "" {
+				return fmt.Errorf("struct成员标识符不能为空")
+			}
+			memberType := member.GetDataType()
+			if memberType.Type == "struct" || memberType.Type == "array" {
+				return fmt.Errorf("struct成员[%s]不支持%s类型", member.Identifier, memberType.Type)
+			}
+			if err := m.validateDataType(memberType); err != nil {
+				return fmt.Errorf("struct成员[%s]: %v", member.Identifier, err)
+			}
+		}
+
+	case "array":
+		if dt.Specs.Size <= 0 {
+			return fmt.Errorf("array类型需要大于0的size")
+		}
+		if dt.Specs.Item == nil {
+			return fmt.Errorf("array类型缺少item定义")
+		}
+		switch dt.Specs.Item.Type {
+		case "int", "float", "double", "text", "struct":
+		default:
+			return fmt.Errorf("array元素不支持%s类型", dt.Specs.Item.Type)
+		}
+		if err := m.validateDataType(*dt.Specs.Item); err != nil {
+			return fmt.Errorf("array元素: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,298 @@
+package tsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 平台内置的属性上报事件和属性读写服务，由SDK直接处理，不参与模拟
+var builtinMethods = map[string]bool{
+	"thing.event.property.post":  true,
+	"thing.service.property.set": true,
+	"thing.service.property.get": true,
+}
+
+// Normalize 规范化TSL模型：合并services和自定义功能模块，统一dataType/data_type等重复字段，并过滤平台内置的事件和服务
+func (m *TSLModel) Normalize() {
+	prefix := ""
+	if m.FunctionBlockID != "" {
+		prefix = m.FunctionBlockID + ":"
+	}
+
+	properties := normalizeProperties(m.Properties, prefix)
+	events := normalizeEvents(m.Events, prefix)
+	actions := normalizeActions(append(m.Actions, m.Services...), prefix)
+
+	for _, block := range m.FunctionBlocks {
+		blockPrefix := block.FunctionBlockID + ":"
+		properties = append(properties, normalizeProperties(block.Properties, blockPrefix)...)
+		events = append(events, normalizeEvents(block.Events, blockPrefix)...)
+		actions = append(actions, normalizeActions(append(block.Actions, block.Services...), blockPrefix)...)
+	}
+
+	m.Properties = properties
+	m.Events = events
+	m.Actions = actions
+	m.Services = nil
+	m.FunctionBlocks = nil
+}
+
+// GetProductKey 获取TSL中声明的ProductKey，未声明时返回空字符串
+func (m *TSLModel) GetProductKey() string {
+	if m.Profile == nil {
+		return ""
+	}
+	return m.Profile.ProductKey
+}
+
+// prefixIdentifier 为自定义功能模块的标识符添加模块前缀，已有前缀时不重复添加
+func prefixIdentifier(identifier, prefix string) string {
+	if prefix == "" || strings.HasPrefix(identifier, prefix) {
+		return identifier
+	}
+	return prefix + identifier
+}
+
+// normalizeProperties 规范化属性列表
+func normalizeProperties(properties []Property, prefix string) []Property {
+	result := make([]Property, 0, len(properties))
+	for _, prop := range properties {
+		prop.Identifier = prefixIdentifier(prop.Identifier, prefix)
+		prop.DataType = normalizeDataType(prop.GetDataType())
+		prop.DataType2 = DataType{}
+		result = append(result, prop)
+	}
+	return result
+}
+
+// normalizeEvents 规范化事件列表，过滤属性上报事件
+func normalizeEvents(events []Event, prefix string) []Event {
+	result := make([]Event, 0, len(events))
+	for _, event := range events {
+		if builtinMethods[event.Method] {
+			continue
+		}
+		event.Identifier = prefixIdentifier(event.Identifier, prefix)
+		event.EventType = event.GetEventType()
+		event.EventType2 = ""
+
+		outputData := event.GetOutputData()
+		event.OutputData = make([]EventParam, 0, len(outputData))
+		for _, param := range outputData {
+			param.DataType = normalizeDataType(param.GetDataType())
+			param.DataType2 = DataType{}
+			event.OutputData = append(event.OutputData, param)
+		}
+		event.OutputData2 = nil
+		result = append(result, event)
+	}
+	return result
+}
+
+// normalizeActions 规范化服务列表，过滤属性读写服务
+func normalizeActions(actions []Action, prefix string) []Action {
+	result := make([]Action, 0, len(actions))
+	for _, action := range actions {
+		if builtinMethods[action.Method] {
+			continue
+		}
+		action.Identifier = prefixIdentifier(action.Identifier, prefix)
+		action.InputData = normalizeActionParams(action.GetInputData())
+		action.InputData2 = nil
+		action.OutputData = normalizeActionParams(action.GetOutputData())
+		action.OutputData2 = nil
+		result = append(result, action)
+	}
+	return result
+}
+
+// normalizeActionParams 规范化服务参数列表
+func normalizeActionParams(params []ActionParam) []ActionParam {
+	result := make([]ActionParam, 0, len(params))
+	for _, param := range params {
+		param.DataType = normalizeDataType(param.GetDataType())
+		param.DataType2 = DataType{}
+		result = append(result, param)
+	}
+	return result
+}
+
+// normalizeDataType 规范化数据类型：补全enum取值和bool显示文本，递归处理struct成员和array元素
+func normalizeDataType(dt DataType) DataType {
+	switch dt.Type {
+	case "enum":
+		dt.Specs.EnumItems = dt.Specs.GetEnumItems()
+	case "bool":
+		if dt.Specs.True == "" {
+			dt.Specs.True = dt.Specs.EnumItems["1"]
+		}
+		if dt.Specs.False == "" {
+			dt.Specs.False = dt.Specs.EnumItems["0"]
+		}
+	case "struct":
+		members := make([]StructMember, 0, len(dt.Members))
+		for _, member := range dt.Members {
+			member.DataType = normalizeDataType(member.GetDataType())
+			member.DataType2 = DataType{}
+			members = append(members, member)
+		}
+		dt.Members = members
+	case "array":
+		if dt.Specs.Item != nil {
+			item := normalizeDataType(*dt.Specs.Item)
+			dt.Specs.Item = &item
+		}
+	}
+	return dt
+}
+
+// GetEnumItems 获取enum的取值与显示文本，兼容enum字段中的JSON对象({"0":"制冷"})和逗号分隔的键值对(0:制冷,1:制热)
+func (ds DataSpecs) GetEnumItems() map[string]string {
+	if len(ds.EnumItems) > 0 {
+		return ds.EnumItems
+	}
+
+	enum := strings.TrimSpace(ds.Enum)
+	if enum == "" {
+		return nil
+	}
+
+	items := make(map[string]string)
+	if strings.HasPrefix(enum, "{") {
+		if err := json.Unmarshal([]byte(enum), &items); err != nil {
+			return nil
+		}
+		return items
+	}
+
+	for _, pair := range strings.Split(enum, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+		key := strings.TrimSpace(parts[0])
+		if key == "" {
+			continue
+		}
+		if len(parts) == 2 {
+			items[key] = strings.TrimSpace(parts[1])
+		} else {
+			items[key] = key
+		}
+	}
+	return items
+}
+
+// GetEnumKeys 获取按数值排序的enum取值
+func (ds DataSpecs) GetEnumKeys() []string {
+	items := ds.GetEnumItems()
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// UnmarshalJSON 解析数据规格，兼容以字符串表示的数值("min": "0")和enum/bool的取值映射
+func (ds *DataSpecs) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*ds = DataSpecs{}
+	for key, value := range raw {
+		var err error
+		switch key {
+		case "length":
+			ds.Length, err = parseSpecInt(value)
+		case "accuracy":
+			ds.Accuracy, err = parseSpecInt(value)
+		case "size":
+			ds.Size, err = parseSpecInt(value)
+		case "min":
+			ds.Min, err = parseSpecNumber(value)
+		case "max":
+			ds.Max, err = parseSpecNumber(value)
+		case "step":
+			ds.Step, err = parseSpecNumber(value)
+		case "unit":
+			ds.Unit = parseSpecString(value)
+		case "unitName":
+			ds.UnitName = parseSpecString(value)
+		case "enum":
+			ds.Enum = parseSpecString(value)
+		case "true":
+			ds.True = parseSpecString(value)
+		case "false":
+			ds.False = parseSpecString(value)
+		case "enumValue":
+			ds.EnumValue = parseSpecString(value)
+		case "item":
+			ds.Item = &DataType{}
+			err = json.Unmarshal(value, ds.Item)
+		default:
+			// enum和bool的取值映射，键为整数
+			if _, convErr := strconv.Atoi(key); convErr == nil {
+				if ds.EnumItems == nil {
+					ds.EnumItems = make(map[string]string)
+				}
+				ds.EnumItems[key] = parseSpecString(value)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("规格[%s]无效: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON 序列化数据规格，enum/bool的取值映射写回规格对象
+func (ds DataSpecs) MarshalJSON() ([]byte, error) {
+	type plainSpecs DataSpecs
+	data, err := json.Marshal(plainSpecs(ds))
+	if err != nil || len(ds.EnumItems) == 0 {
+		return data, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, label := range ds.EnumItems {
+		fields[key] = label
+	}
+	return json.Marshal(fields)
+}
+
+// parseSpecNumber 解析规格中的数值，支持数字和数字字符串
+func parseSpecNumber(raw json.RawMessage) (float64, error) {
+	str := strings.TrimSpace(parseSpecString(raw))
+	if str == "" || str == "null" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(str, 64)
+}
+
+// parseSpecInt 解析规格中的整数，支持数字和数字字符串
+func parseSpecInt(raw json.RawMessage) (int, error) {
+	f, err := parseSpecNumber(raw)
+	return int(f), err
+}
+
+// parseSpecString 解析规格中的字符串，非字符串值按原文返回
+func parseSpecString(raw json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+	return string(raw)
+}